@@ -0,0 +1,214 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/iij/dagtools/env"
+	"github.com/iij/dagtools/ini"
+)
+
+const (
+	// EnvAccessKeyID and EnvSecretAccessKey are names of the environment variables of the credentials.
+	EnvAccessKeyID     = "DAGTOOLS_ACCESS_KEY_ID"
+	EnvSecretAccessKey = "DAGTOOLS_SECRET_ACCESS_KEY"
+
+	defaultCredentialsSection = "default"
+)
+
+var (
+	// ErrNoCredentials is returned by a CredentialsProvider which has no credentials.
+	ErrNoCredentials = errors.New("no credentials")
+)
+
+// Credentials is a pair of keys to sign requests.
+type Credentials struct {
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	// Source is a name of the provider which supplied the credentials.
+	Source string `json:"-"`
+}
+
+// CredentialsProvider supplies credentials.
+type CredentialsProvider interface {
+	// Retrieve returns the credentials, or ErrNoCredentials if the provider has none.
+	Retrieve() (*Credentials, error)
+}
+
+// ChainCredentialsProvider tries the providers in order and returns the first credentials found.
+type ChainCredentialsProvider []CredentialsProvider
+
+// Retrieve returns the credentials of the first provider which does not return ErrNoCredentials.
+func (chain ChainCredentialsProvider) Retrieve() (*Credentials, error) {
+	for _, p := range chain {
+		creds, err := p.Retrieve()
+		if err != ErrNoCredentials {
+			return creds, err
+		}
+	}
+	return nil, ErrNoCredentials
+}
+
+// EnvCredentialsProvider reads the credentials from DAGTOOLS_ACCESS_KEY_ID and DAGTOOLS_SECRET_ACCESS_KEY.
+type EnvCredentialsProvider struct{}
+
+// Retrieve returns the credentials if the environment variables are set.
+func (p *EnvCredentialsProvider) Retrieve() (*Credentials, error) {
+	accessKeyID, secretAccessKey := os.Getenv(EnvAccessKeyID), os.Getenv(EnvSecretAccessKey)
+	if accessKeyID == "" && secretAccessKey == "" {
+		return nil, ErrNoCredentials
+	}
+	if accessKeyID == "" || secretAccessKey == "" {
+		return nil, fmt.Errorf("both %s and %s must be set", EnvAccessKeyID, EnvSecretAccessKey)
+	}
+	return &Credentials{AccessKeyID: accessKeyID, SecretAccessKey: secretAccessKey, Source: "environment"}, nil
+}
+
+// FileCredentialsProvider reads the credentials from a section of an INI file:
+//
+//	[default]
+//	accessKeyId = ...
+//	secretAccessKey = ...
+//
+// The file must not be accessible by other users.
+type FileCredentialsProvider struct {
+	Filename string
+	// Section is a name of the section. The default is "default".
+	Section string
+	// IgnoreMissing makes Retrieve return ErrNoCredentials instead of an error if the file does not exist.
+	IgnoreMissing bool
+}
+
+// Retrieve returns the credentials in the file.
+func (p *FileCredentialsProvider) Retrieve() (*Credentials, error) {
+	if p.Filename == "" {
+		return nil, ErrNoCredentials
+	}
+	stat, err := os.Stat(p.Filename)
+	if err != nil {
+		if os.IsNotExist(err) && p.IgnoreMissing {
+			return nil, ErrNoCredentials
+		}
+		return nil, err
+	}
+	if !stat.Mode().IsRegular() {
+		return nil, fmt.Errorf("credentials file is not a regular file: %s", p.Filename)
+	}
+	if runtime.GOOS != "windows" && stat.Mode().Perm()&0077 != 0 {
+		return nil, fmt.Errorf("credentials file must not be accessible by other users (permissions %#o): %s", stat.Mode().Perm(), p.Filename)
+	}
+	config, err := ini.LoadFile(p.Filename)
+	if err != nil {
+		return nil, err
+	}
+	name := p.Section
+	if name == "" {
+		name = defaultCredentialsSection
+	}
+	if !config.HasSection(name) {
+		return nil, ErrNoCredentials
+	}
+	s := config.Section(name)
+	creds := &Credentials{
+		AccessKeyID:     s.Get("accessKeyId", ""),
+		SecretAccessKey: s.Get("secretAccessKey", ""),
+		Source:          p.Filename,
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil, fmt.Errorf("accessKeyId and secretAccessKey are required in [%s] of %s", name, p.Filename)
+	}
+	return creds, nil
+}
+
+// ProcessCredentialsProvider runs an external command which prints the credentials in JSON to stdout:
+//
+//	{"AccessKeyId": "...", "SecretAccessKey": "..."}
+//
+// The command is run by the shell (sh -c, or cmd /C on Windows), and its stderr is passed through.
+type ProcessCredentialsProvider struct {
+	Command string
+	Context context.Context
+}
+
+// Retrieve runs the command and returns the credentials in its output.
+func (p *ProcessCredentialsProvider) Retrieve() (*Credentials, error) {
+	if p.Command == "" {
+		return nil, ErrNoCredentials
+	}
+	ctx := p.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.CommandContext(ctx, "cmd", "/C", p.Command)
+	} else {
+		c = exec.CommandContext(ctx, "sh", "-c", p.Command)
+	}
+	var stdout bytes.Buffer
+	c.Stdout = &stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return nil, fmt.Errorf("credentialProcess failed: %v", err)
+	}
+	creds := new(Credentials)
+	if err := json.Unmarshal(stdout.Bytes(), creds); err != nil {
+		return nil, fmt.Errorf("invalid output of credentialProcess: %v", err)
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil, errors.New("invalid output of credentialProcess: AccessKeyId and SecretAccessKey are required")
+	}
+	creds.Source = "credentialProcess"
+	return creds, nil
+}
+
+// StaticCredentialsProvider returns the fixed credentials, e.g., accessKeyId and secretAccessKey in dagtools.ini.
+type StaticCredentialsProvider struct {
+	Credentials
+}
+
+// Retrieve returns the credentials if the access key ID is not empty.
+func (p *StaticCredentialsProvider) Retrieve() (*Credentials, error) {
+	if p.AccessKeyID == "" {
+		return nil, ErrNoCredentials
+	}
+	creds := p.Credentials
+	return &creds, nil
+}
+
+// NewDefaultCredentialsProvider returns a chain of the environment variables, the credentials file
+// ([storage] credentialsFile, default: ~/.dagtools/credentials), the external command ([storage] credentialProcess)
+// and the accessKeyId and secretAccessKey in the section. The section of the credentials file is the profile name
+// or "default".
+func NewDefaultCredentialsProvider(env *env.Environment, s *ini.Section) CredentialsProvider {
+	home, _ := os.UserHomeDir()
+	file := &FileCredentialsProvider{Filename: s.Get("credentialsFile", ""), Section: env.Profile}
+	if _, ok := (*s)["credentialsFile"]; !ok && home != "" {
+		file.Filename = filepath.Join(home, ".dagtools", "credentials")
+		file.IgnoreMissing = true
+	} else if strings.HasPrefix(file.Filename, "~/") && home != "" {
+		file.Filename = filepath.Join(home, file.Filename[2:])
+	}
+	source := env.Config.Filename
+	if source == "" {
+		source = "configuration"
+	}
+	return ChainCredentialsProvider{
+		&EnvCredentialsProvider{},
+		file,
+		&ProcessCredentialsProvider{Command: s.Get("credentialProcess", ""), Context: env.Context},
+		&StaticCredentialsProvider{Credentials{
+			AccessKeyID:     s.Get("accessKeyId", ""),
+			SecretAccessKey: s.Get("secretAccessKey", ""),
+			Source:          source,
+		}},
+	}
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func writeCredentialsFile(t *testing.T, content string, perm os.FileMode) string {
+	dir, err := ioutil.TempDir("", "dagtools-credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "credentials")
+	if err := ioutil.WriteFile(filename, []byte(content), perm); err != nil {
+		t.Fatal(err)
+	}
+	os.Chmod(filename, perm)
+	return filename
+}
+
+func setCredentialsEnv(accessKeyID, secretAccessKey string) func() {
+	os.Setenv(EnvAccessKeyID, accessKeyID)
+	os.Setenv(EnvSecretAccessKey, secretAccessKey)
+	return func() {
+		os.Unsetenv(EnvAccessKeyID)
+		os.Unsetenv(EnvSecretAccessKey)
+	}
+}
+
+func TestEnvCredentialsProvider(t *testing.T) {
+	defer setCredentialsEnv("ENV00000000000000000", "EnvSecret")()
+	creds, err := (&EnvCredentialsProvider{}).Retrieve()
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	assertEquals(t, "The access key ID is invalid.", creds.AccessKeyID, "ENV00000000000000000")
+	assertEquals(t, "The secret access key is invalid.", creds.SecretAccessKey, "EnvSecret")
+}
+
+func TestEnvCredentialsProviderNotSet(t *testing.T) {
+	defer setCredentialsEnv("", "")()
+	_, err := (&EnvCredentialsProvider{}).Retrieve()
+	assertEquals(t, "Should return ErrNoCredentials without the environment variables.", err, ErrNoCredentials)
+}
+
+func TestEnvCredentialsProviderOnlyAccessKeyID(t *testing.T) {
+	defer setCredentialsEnv("ENV00000000000000000", "")()
+	if _, err := (&EnvCredentialsProvider{}).Retrieve(); err == nil || err == ErrNoCredentials {
+		t.Errorf("Should return an error without the secret access key. %v", err)
+	}
+}
+
+func TestFileCredentialsProvider(t *testing.T) {
+	filename := writeCredentialsFile(t, "[default]\naccessKeyId = FILE0000000000000000\nsecretAccessKey = FileSecret\n\n[staging]\naccessKeyId = STG\nsecretAccessKey = StgSecret\n", 0600)
+	defer os.RemoveAll(filepath.Dir(filename))
+	creds, err := (&FileCredentialsProvider{Filename: filename}).Retrieve()
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	assertEquals(t, "The access key ID is invalid.", creds.AccessKeyID, "FILE0000000000000000")
+	assertEquals(t, "The secret access key is invalid.", creds.SecretAccessKey, "FileSecret")
+
+	creds, err = (&FileCredentialsProvider{Filename: filename, Section: "staging"}).Retrieve()
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	assertEquals(t, "Should read the specified section.", creds.AccessKeyID, "STG")
+
+	_, err = (&FileCredentialsProvider{Filename: filename, Section: "production"}).Retrieve()
+	assertEquals(t, "Should return ErrNoCredentials without the section.", err, ErrNoCredentials)
+}
+
+func TestFileCredentialsProviderPermission(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions are not checked on Windows")
+	}
+	filename := writeCredentialsFile(t, "[default]\naccessKeyId = FILE0000000000000000\nsecretAccessKey = FileSecret\n", 0644)
+	defer os.RemoveAll(filepath.Dir(filename))
+	if _, err := (&FileCredentialsProvider{Filename: filename}).Retrieve(); err == nil || err == ErrNoCredentials {
+		t.Errorf("Should return an error if the file is readable by other users. %v", err)
+	}
+}
+
+func TestFileCredentialsProviderMissing(t *testing.T) {
+	p := &FileCredentialsProvider{Filename: "test_file/noSuchCredentials"}
+	if _, err := p.Retrieve(); err == nil || err == ErrNoCredentials {
+		t.Errorf("Should return an error if the specified file does not exist. %v", err)
+	}
+	p.IgnoreMissing = true
+	_, err := p.Retrieve()
+	assertEquals(t, "Should ignore the missing default file.", err, ErrNoCredentials)
+}
+
+func TestProcessCredentialsProvider(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test command requires sh")
+	}
+	p := &ProcessCredentialsProvider{Command: `echo '{"Version": 1, "AccessKeyId": "PROC0000000000000000", "SecretAccessKey": "ProcSecret"}'`}
+	creds, err := p.Retrieve()
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	assertEquals(t, "The access key ID is invalid.", creds.AccessKeyID, "PROC0000000000000000")
+	assertEquals(t, "The secret access key is invalid.", creds.SecretAccessKey, "ProcSecret")
+}
+
+func TestProcessCredentialsProviderErr(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test command requires sh")
+	}
+	for _, command := range []string{"exit 1", "echo not-json", `echo '{"AccessKeyId": "PROC0000000000000000"}'`} {
+		if _, err := (&ProcessCredentialsProvider{Command: command}).Retrieve(); err == nil || err == ErrNoCredentials {
+			t.Errorf("Should return an error. command: %q, error: %v", command, err)
+		}
+	}
+}
+
+func TestChainCredentialsProvider(t *testing.T) {
+	chain := ChainCredentialsProvider{
+		&EnvCredentialsProvider{},
+		&FileCredentialsProvider{},
+		&StaticCredentialsProvider{Credentials{AccessKeyID: "INI", SecretAccessKey: "IniSecret"}},
+	}
+	creds, err := chain.Retrieve()
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	assertEquals(t, "Should fall back to the static credentials.", creds.AccessKeyID, "INI")
+
+	defer setCredentialsEnv("ENV00000000000000000", "EnvSecret")()
+	creds, err = chain.Retrieve()
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	assertEquals(t, "Should prefer the environment variables.", creds.AccessKeyID, "ENV00000000000000000")
+}
+
+func TestNewStorageClientWithEnvCredentials(t *testing.T) {
+	defer setCredentialsEnv("ENV00000000000000000", "EnvSecret")()
+	client := newMock()
+	accessKeyID, secretAccessKey, err := client.accessKeys()
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	assertEquals(t, "Should prefer the environment variables to the ini values.", accessKeyID, "ENV00000000000000000")
+	assertEquals(t, "Should prefer the environment variables to the ini values.", secretAccessKey, "EnvSecret")
+}
+
+func TestNewStorageClientRunsCredentialProcessLazily(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test command requires sh")
+	}
+	dir, err := ioutil.TempDir("", "dagtools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	count := filepath.Join(dir, "count")
+	e := newAnonymousMockEnvironment()
+	e.Config.Set("storage", "credentialsFile", "")
+	e.Config.Set("storage", "credentialProcess",
+		`echo run >> `+count+`; echo '{"AccessKeyId": "PROC0000000000000000", "SecretAccessKey": "ProcSecret"}'`)
+	_client, _ := NewStorageClient(&e)
+	client := _client.(*DefaultStorageClient)
+	if _, err := os.Stat(count); !os.IsNotExist(err) {
+		t.Fatal("Should not run credentialProcess until a request is signed.")
+	}
+	copied := client.WithContext(context.Background()).(*DefaultStorageClient)
+	for _, c := range []*DefaultStorageClient{client, copied, client} {
+		if _, err := c.PresignURL("GET", "mybucket", "foo", time.Now().Add(time.Hour), nil); err != nil {
+			t.Fatal("unknown error:", err)
+		}
+	}
+	b, _ := ioutil.ReadFile(count)
+	assertEquals(t, "Should run credentialProcess once among the copies of the client.", string(b), "run\n")
+}
+
+func TestNewStorageClientWithInvalidCredentials(t *testing.T) {
+	e := newMockEnvironment()
+	e.Config.Set("storage", "credentialsFile", "test_file/noSuchCredentials")
+	_client, _ := NewStorageClient(&e)
+	client := _client.(*DefaultStorageClient)
+	if _, err := client.DoAndRetry(nil, nil); err == nil {
+		t.Error("Should return the error of loading the credentials.")
+	}
+	if _, err := client.PresignURL("GET", "mybucket", "foo", time.Now().Add(time.Hour), nil); err == nil {
+		t.Error("Should return the error of loading the credentials.")
+	}
+}
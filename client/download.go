@@ -0,0 +1,86 @@
+package client
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+const downloadStateSuffix = ".dagtools-download"
+
+// DownloadStateFilename returns the name of the file to save the progress of downloading to the file.
+func DownloadStateFilename(filename string) string {
+	return filename + downloadStateSuffix
+}
+
+// downloadState is the progress of a download saved to resume it.
+type downloadState struct {
+	Bucket       string    `json:"bucket"`
+	Key          string    `json:"key"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"lastModified"`
+	Size         int64     `json:"size"`
+	ChunkSize    int64     `json:"chunkSize"`
+	// Parts is the part numbers which have been downloaded.
+	Parts []int `json:"parts"`
+}
+
+func newDownloadState(bucket, key string, summary *ObjectSummary, chunkSize int64) *downloadState {
+	return &downloadState{
+		Bucket:       bucket,
+		Key:          key,
+		ETag:         summary.ETag,
+		LastModified: summary.LastModified,
+		Size:         summary.Size,
+		ChunkSize:    chunkSize,
+		Parts:        []int{},
+	}
+}
+
+// loadDownloadState loads a download state from the file. If the file does not exist, it returns nil.
+func loadDownloadState(filename string) (*downloadState, error) {
+	raw, err := ioutil.ReadFile(DownloadStateFilename(filename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	state := new(downloadState)
+	if err = json.Unmarshal(raw, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// save writes the download state to the file atomically.
+func (s *downloadState) save(filename string) error {
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	tmp := filename + ".tmp"
+	if err = ioutil.WriteFile(tmp, raw, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
+
+// matches returns true if the state is for the object and the object has not been modified.
+func (s *downloadState) matches(bucket, key string, summary *ObjectSummary) bool {
+	return s.Bucket == bucket && s.Key == key &&
+		s.ETag == summary.ETag &&
+		s.LastModified.Equal(summary.LastModified) &&
+		s.Size == summary.Size &&
+		s.ChunkSize > 0
+}
+
+// completed returns a set of the downloaded part numbers.
+func (s *downloadState) completed() map[int]bool {
+	done := make(map[int]bool)
+	for _, n := range s.Parts {
+		done[n] = true
+	}
+	return done
+}
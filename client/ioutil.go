@@ -24,3 +24,36 @@ func (r DigestReader) Read(p []byte) (n int, err error) {
 func (r DigestReader) Digest() []byte {
 	return r.h.Sum(nil)
 }
+
+// offsetWriter writes to the underlying io.WriterAt sequentially from the offset
+type offsetWriter struct {
+	w   io.WriterAt
+	off int64
+}
+
+// Write writes bytes at the current offset and advances it
+func (w *offsetWriter) Write(p []byte) (n int, err error) {
+	n, err = w.w.WriteAt(p, w.off)
+	w.off += int64(n)
+	return
+}
+
+// countingReadCloser calls count with the number of bytes of each read
+type countingReadCloser struct {
+	rc    io.ReadCloser
+	count func(n int64)
+}
+
+// Read reads bytes from the underlying io.ReadCloser and counts them
+func (r *countingReadCloser) Read(p []byte) (n int, err error) {
+	n, err = r.rc.Read(p)
+	if n > 0 {
+		r.count(int64(n))
+	}
+	return
+}
+
+// Close closes the underlying io.ReadCloser
+func (r *countingReadCloser) Close() error {
+	return r.rc.Close()
+}
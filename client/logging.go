@@ -0,0 +1,146 @@
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iij/dagtools/env"
+)
+
+// Log levels of the events of the client.
+const (
+	levelDebug = env.LevelDebug
+	levelInfo  = env.LevelInfo
+	levelWarn  = env.LevelWarn
+	levelError = env.LevelError
+)
+
+// operationNames maps "METHOD /bucket/key?subresource" patterns to the names of the operations.
+var operationNames = map[string]string{
+	"GET /":                         "ListBuckets",
+	"GET /?space":                   "GetStorageSpace",
+	"GET /?traffic":                 "ListNetworkTraffics",
+	"PUT /bucket":                   "CreateBucket",
+	"DELETE /bucket":                "DeleteBucket",
+	"HEAD /bucket":                  "HeadBucket",
+	"GET /bucket":                   "ListObjects",
+	"GET /bucket?uploads":           "ListMultipartUploads",
+	"POST /bucket?delete":           "DeleteObjects",
+	"GET /bucket?acl":               "GetBucketAcl",
+	"PUT /bucket?acl":               "PutBucketAcl",
+	"GET /bucket?cors":              "GetBucketCors",
+	"PUT /bucket?cors":              "PutBucketCors",
+	"DELETE /bucket?cors":           "DeleteBucketCors",
+	"GET /bucket?policy":            "GetBucketPolicy",
+	"PUT /bucket?policy":            "PutBucketPolicy",
+	"DELETE /bucket?policy":         "DeleteBucketPolicy",
+	"GET /bucket?website":           "GetBucketWebsite",
+	"PUT /bucket?website":           "PutBucketWebsite",
+	"DELETE /bucket?website":        "DeleteBucketWebsite",
+	"GET /bucket/key":               "GetObject",
+	"PUT /bucket/key":               "PutObject",
+	"HEAD /bucket/key":              "HeadObject",
+	"DELETE /bucket/key":            "DeleteObject",
+	"GET /bucket/key?acl":           "GetObjectAcl",
+	"PUT /bucket/key?acl":           "PutObjectAcl",
+	"POST /bucket/key?uploads":      "InitiateMultipartUpload",
+	"PUT /bucket/key?uploadId":      "UploadPart",
+	"GET /bucket/key?uploadId":      "ListParts",
+	"POST /bucket/key?uploadId":     "CompleteMultipartUpload",
+	"DELETE /bucket/key?uploadId":   "AbortMultipartUpload",
+	"PUT /bucket/key?x-copy-source": "CopyObject",
+}
+
+// operationName returns the name of the operation of the request, e.g., "PutObject".
+func operationName(req *http.Request) string {
+	bucket, key := bucketAndKey(req.URL)
+	pattern := req.Method + " /"
+	if bucket != "" {
+		pattern += "bucket"
+	}
+	if key != "" {
+		pattern += "/key"
+	}
+	for _, sub := range []string{"acl", "cors", "policy", "website", "uploads", "uploadId", "delete", "space", "traffic"} {
+		if _, ok := req.URL.Query()[sub]; ok {
+			pattern += "?" + sub
+			break
+		}
+	}
+	for name := range req.Header {
+		if strings.HasSuffix(strings.ToLower(name), "-copy-source") {
+			pattern += "?x-copy-source"
+		}
+	}
+	if name, ok := operationNames[pattern]; ok {
+		return name
+	}
+	return req.Method
+}
+
+// bucketAndKey returns the bucket and the key in the path of the URL.
+func bucketAndKey(u *url.URL) (bucket, key string) {
+	path := strings.TrimPrefix(u.Path, "/")
+	if i := strings.Index(path, "/"); i >= 0 {
+		return path[:i], path[i+1:]
+	}
+	return path, ""
+}
+
+// requestID returns the request ID in the response header.
+func requestID(header http.Header) string {
+	if id := header.Get("x-iijgio-request-id"); id != "" {
+		return id
+	}
+	return header.Get("x-amz-request-id")
+}
+
+// msSince returns the elapsed time since t in milliseconds.
+func msSince(t time.Time) int64 {
+	return int64(time.Since(t) / time.Millisecond)
+}
+
+// eventLogger returns the EventLogger of the client. If it is nil, the events are written to Logger as text.
+func (cli *DefaultStorageClient) eventLogger() env.EventLogger {
+	if cli.EventLogger != nil {
+		return cli.EventLogger
+	}
+	logger := cli.Logger
+	if logger == nil {
+		logger = log.New(ioutil.Discard, "", 0)
+	}
+	return &env.TextLogger{Logger: logger, Level: levelInfo}
+}
+
+// logf records a free-form message of the level.
+func (cli *DefaultStorageClient) logf(level env.Level, format string, v ...interface{}) {
+	cli.eventLogger().Log(level, strings.TrimRight(fmt.Sprintf(format, v...), "\n"))
+}
+
+// logHeader records the HTTP headers as an event of the debug level.
+func (cli *DefaultStorageClient) logHeader(msg string, header http.Header) {
+	var fields []interface{}
+	var names []string
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		key := name
+		if strings.HasPrefix(key, "X-") {
+			key = strings.ToLower(key)
+		}
+		value := strings.Join(header[name], ",")
+		if name == "Authorization" {
+			value = "..."
+		}
+		fields = append(fields, key, value)
+	}
+	cli.eventLogger().Log(levelDebug, msg, fields...)
+}
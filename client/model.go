@@ -2,8 +2,10 @@ package client
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -26,6 +28,16 @@ func (e ErrorResponse) Error() string {
 	return msg
 }
 
+// MultipartUploadError is returned when a multipart upload failed and remains to be resumed.
+type MultipartUploadError struct {
+	Upload *MultipartUpload
+	Err    error
+}
+
+func (e *MultipartUploadError) Error() string {
+	return fmt.Sprintf("%v (UploadId: %s)", e.Err, e.Upload.UploadID)
+}
+
 // Bucket is meta information of `Bucket` in dagrin
 type Bucket struct {
 	Name         string    `xml:"Name"`
@@ -224,6 +236,316 @@ type CompleteMultipartUploadResult struct {
 	ETag     string `xml:"ETag"`
 }
 
+// CopyObjectResult is response of PUT Object - Copy request.
+type CopyObjectResult struct {
+	XMLName      xml.Name  `xml:"CopyObjectResult"`
+	LastModified time.Time `xml:"LastModified"`
+	ETag         string    `xml:"ETag"`
+}
+
+// ContentRange is a byte range of an object in a partial response.
+type ContentRange struct {
+	First int64
+	Last  int64
+	// Total is the size of the whole object, -1 if unknown.
+	Total int64
+}
+
+// Length returns number of bytes in the range.
+func (cr *ContentRange) Length() int64 {
+	return cr.Last - cr.First + 1
+}
+
+func (cr *ContentRange) String() string {
+	total := "*"
+	if cr.Total >= 0 {
+		total = strconv.FormatInt(cr.Total, 10)
+	}
+	return fmt.Sprintf("bytes %d-%d/%s", cr.First, cr.Last, total)
+}
+
+// ParseContentRange parses a Content-Range header value (e.g., "bytes 0-1023/146515").
+func ParseContentRange(s string) (*ContentRange, error) {
+	invalid := fmt.Errorf("invalid Content-Range: %q", s)
+	if !strings.HasPrefix(s, "bytes ") {
+		return nil, invalid
+	}
+	xs := strings.SplitN(strings.TrimPrefix(s, "bytes "), "/", 2)
+	if len(xs) != 2 {
+		return nil, invalid
+	}
+	pos := strings.SplitN(xs[0], "-", 2)
+	if len(pos) != 2 {
+		return nil, invalid
+	}
+	var (
+		cr  = new(ContentRange)
+		err error
+	)
+	if cr.First, err = strconv.ParseInt(pos[0], 10, 64); err != nil {
+		return nil, invalid
+	}
+	if cr.Last, err = strconv.ParseInt(pos[1], 10, 64); err != nil || cr.Last < cr.First {
+		return nil, invalid
+	}
+	cr.Total = -1
+	if xs[1] != "*" {
+		if cr.Total, err = strconv.ParseInt(xs[1], 10, 64); err != nil {
+			return nil, invalid
+		}
+	}
+	return cr, nil
+}
+
+// Canned ACLs
+const (
+	CannedACLPrivate                = "private"
+	CannedACLPublicRead             = "public-read"
+	CannedACLPublicReadWrite        = "public-read-write"
+	CannedACLAuthenticatedRead      = "authenticated-read"
+	CannedACLBucketOwnerRead        = "bucket-owner-read"
+	CannedACLBucketOwnerFullControl = "bucket-owner-full-control"
+)
+
+// CannedACLs is a list of the canned ACLs.
+var CannedACLs = []string{
+	CannedACLPrivate,
+	CannedACLPublicRead,
+	CannedACLPublicReadWrite,
+	CannedACLAuthenticatedRead,
+	CannedACLBucketOwnerRead,
+	CannedACLBucketOwnerFullControl,
+}
+
+// IsCannedACL returns true if acl is one of the canned ACLs.
+func IsCannedACL(acl string) bool {
+	for _, v := range CannedACLs {
+		if v == acl {
+			return true
+		}
+	}
+	return false
+}
+
+const xmlSchemaInstance = "http://www.w3.org/2001/XMLSchema-instance"
+
+// AccessControlPolicy is an access control list (ACL) of a bucket or an object.
+type AccessControlPolicy struct {
+	XMLName xml.Name `xml:"AccessControlPolicy"`
+	Owner   Owner    `xml:"Owner"`
+	Grants  []Grant  `xml:"AccessControlList>Grant"`
+}
+
+// Grant is a permission given to a grantee.
+type Grant struct {
+	Grantee    Grantee `xml:"Grantee"`
+	Permission string  `xml:"Permission"`
+}
+
+func (g Grant) String() string {
+	return fmt.Sprintf("{grantee: %v, permission: %q}", g.Grantee, g.Permission)
+}
+
+// Grantee is a user or a group to be granted a permission.
+// Type is one of "CanonicalUser", "AmazonCustomerByEmail" or "Group".
+type Grantee struct {
+	Type         string `xml:"http://www.w3.org/2001/XMLSchema-instance type,attr"`
+	ID           string `xml:"ID,omitempty"`
+	DisplayName  string `xml:"DisplayName,omitempty"`
+	EmailAddress string `xml:"EmailAddress,omitempty"`
+	URI          string `xml:"URI,omitempty"`
+}
+
+// MarshalXML encodes the grantee with the "xsi" namespace prefix which the server expects.
+func (g Grantee) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Attr = []xml.Attr{
+		{Name: xml.Name{Local: "xmlns:xsi"}, Value: xmlSchemaInstance},
+		{Name: xml.Name{Local: "xsi:type"}, Value: g.Type},
+	}
+	type grantee struct {
+		ID           string `xml:"ID,omitempty"`
+		DisplayName  string `xml:"DisplayName,omitempty"`
+		EmailAddress string `xml:"EmailAddress,omitempty"`
+		URI          string `xml:"URI,omitempty"`
+	}
+	return e.EncodeElement(grantee{g.ID, g.DisplayName, g.EmailAddress, g.URI}, start)
+}
+
+func (g Grantee) String() string {
+	switch {
+	case g.URI != "":
+		return g.URI
+	case g.EmailAddress != "":
+		return g.EmailAddress
+	case g.DisplayName != "":
+		return fmt.Sprintf("%s (%s)", g.DisplayName, g.ID)
+	}
+	return g.ID
+}
+
+// CORSAllowedMethods is a set of HTTP methods which can be specified in CORSRule.AllowedMethods.
+var CORSAllowedMethods = map[string]bool{
+	"GET":    true,
+	"PUT":    true,
+	"POST":   true,
+	"DELETE": true,
+	"HEAD":   true,
+}
+
+const maxCORSRules = 100
+
+// CORSConfiguration is a cross-origin resource sharing (CORS) configuration of a bucket.
+type CORSConfiguration struct {
+	XMLName xml.Name   `xml:"CORSConfiguration" json:"-"`
+	Rules   []CORSRule `xml:"CORSRule" json:"CORSRules"`
+}
+
+// CORSRule is a rule of the cross-origin requests.
+type CORSRule struct {
+	ID             string   `xml:"ID,omitempty" json:"ID,omitempty"`
+	AllowedOrigins []string `xml:"AllowedOrigin" json:"AllowedOrigins"`
+	AllowedMethods []string `xml:"AllowedMethod" json:"AllowedMethods"`
+	AllowedHeaders []string `xml:"AllowedHeader,omitempty" json:"AllowedHeaders,omitempty"`
+	ExposeHeaders  []string `xml:"ExposeHeader,omitempty" json:"ExposeHeaders,omitempty"`
+	MaxAgeSeconds  int      `xml:"MaxAgeSeconds,omitempty" json:"MaxAgeSeconds,omitempty"`
+}
+
+// Validate checks the configuration before it is sent to the server.
+func (c *CORSConfiguration) Validate() error {
+	if len(c.Rules) == 0 {
+		return errors.New("invalid cors configuration: no rule is specified")
+	}
+	if len(c.Rules) > maxCORSRules {
+		return fmt.Errorf("invalid cors configuration: too many rules (max: %d)", maxCORSRules)
+	}
+	for i, rule := range c.Rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("invalid cors configuration: rule #%d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (r *CORSRule) validate() error {
+	if len(r.ID) > 255 {
+		return errors.New("ID must be at most 255 characters")
+	}
+	if len(r.AllowedOrigins) == 0 {
+		return errors.New("AllowedOrigin is required")
+	}
+	for _, origin := range r.AllowedOrigins {
+		if origin == "" || strings.Count(origin, "*") > 1 {
+			return fmt.Errorf("invalid AllowedOrigin: %q", origin)
+		}
+	}
+	if len(r.AllowedMethods) == 0 {
+		return errors.New("AllowedMethod is required")
+	}
+	for _, method := range r.AllowedMethods {
+		if !CORSAllowedMethods[method] {
+			return fmt.Errorf("invalid AllowedMethod: %q", method)
+		}
+	}
+	for _, header := range r.AllowedHeaders {
+		if header == "" || strings.Count(header, "*") > 1 {
+			return fmt.Errorf("invalid AllowedHeader: %q", header)
+		}
+	}
+	for _, header := range r.ExposeHeaders {
+		if header == "" || strings.Contains(header, "*") {
+			return fmt.Errorf("invalid ExposeHeader: %q", header)
+		}
+	}
+	if r.MaxAgeSeconds < 0 {
+		return fmt.Errorf("invalid MaxAgeSeconds: %d", r.MaxAgeSeconds)
+	}
+	return nil
+}
+
+// WebsiteConfiguration is a static website hosting configuration of a bucket.
+type WebsiteConfiguration struct {
+	XMLName               xml.Name         `xml:"WebsiteConfiguration"`
+	RedirectAllRequestsTo *WebsiteRedirect `xml:"RedirectAllRequestsTo,omitempty"`
+	IndexDocument         string           `xml:"IndexDocument>Suffix,omitempty"`
+	ErrorDocument         string           `xml:"ErrorDocument>Key,omitempty"`
+	RoutingRules          []RoutingRule    `xml:"RoutingRules>RoutingRule,omitempty"`
+}
+
+// MarshalXML encodes the configuration without empty elements which the server rejects.
+func (c WebsiteConfiguration) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type document struct {
+		Suffix string `xml:"Suffix,omitempty"`
+		Key    string `xml:"Key,omitempty"`
+	}
+	type routingRules struct {
+		Rules []RoutingRule `xml:"RoutingRule"`
+	}
+	v := struct {
+		RedirectAllRequestsTo *WebsiteRedirect `xml:"RedirectAllRequestsTo,omitempty"`
+		IndexDocument         *document        `xml:"IndexDocument,omitempty"`
+		ErrorDocument         *document        `xml:"ErrorDocument,omitempty"`
+		RoutingRules          *routingRules    `xml:"RoutingRules,omitempty"`
+	}{RedirectAllRequestsTo: c.RedirectAllRequestsTo}
+	if c.IndexDocument != "" {
+		v.IndexDocument = &document{Suffix: c.IndexDocument}
+	}
+	if c.ErrorDocument != "" {
+		v.ErrorDocument = &document{Key: c.ErrorDocument}
+	}
+	if len(c.RoutingRules) > 0 {
+		v.RoutingRules = &routingRules{c.RoutingRules}
+	}
+	start.Name = xml.Name{Local: "WebsiteConfiguration"}
+	return e.EncodeElement(v, start)
+}
+
+// RoutingRule is a rule to redirect requests which match the condition.
+type RoutingRule struct {
+	Condition *RoutingRuleCondition `xml:"Condition,omitempty"`
+	Redirect  WebsiteRedirect       `xml:"Redirect"`
+}
+
+// RoutingRuleCondition is a condition of RoutingRule.
+type RoutingRuleCondition struct {
+	KeyPrefixEquals             string `xml:"KeyPrefixEquals,omitempty"`
+	HttpErrorCodeReturnedEquals string `xml:"HttpErrorCodeReturnedEquals,omitempty"`
+}
+
+// WebsiteRedirect is a destination of redirected requests.
+type WebsiteRedirect struct {
+	Protocol             string `xml:"Protocol,omitempty"`
+	HostName             string `xml:"HostName,omitempty"`
+	ReplaceKeyPrefixWith string `xml:"ReplaceKeyPrefixWith,omitempty"`
+	ReplaceKeyWith       string `xml:"ReplaceKeyWith,omitempty"`
+	HttpRedirectCode     string `xml:"HttpRedirectCode,omitempty"`
+}
+
+// Validate checks the configuration before it is sent to the server.
+func (c *WebsiteConfiguration) Validate() error {
+	if c.RedirectAllRequestsTo != nil {
+		if c.IndexDocument != "" || c.ErrorDocument != "" || len(c.RoutingRules) > 0 {
+			return errors.New("invalid website configuration: RedirectAllRequestsTo must be specified alone")
+		}
+		if c.RedirectAllRequestsTo.HostName == "" {
+			return errors.New("invalid website configuration: HostName of RedirectAllRequestsTo is required")
+		}
+		return nil
+	}
+	if c.IndexDocument == "" || strings.Contains(c.IndexDocument, "/") {
+		return fmt.Errorf("invalid website configuration: invalid IndexDocument: %q", c.IndexDocument)
+	}
+	for i, rule := range c.RoutingRules {
+		r := rule.Redirect
+		if r.ReplaceKeyPrefixWith != "" && r.ReplaceKeyWith != "" {
+			return fmt.Errorf("invalid website configuration: rule #%d: ReplaceKeyPrefixWith and ReplaceKeyWith cannot be specified together", i+1)
+		}
+		if r == (WebsiteRedirect{}) {
+			return fmt.Errorf("invalid website configuration: rule #%d: Redirect is empty", i+1)
+		}
+	}
+	return nil
+}
+
 type multipleDeletionKey struct {
 	Key string `xml:"Key"`
 }
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PostPolicy is a set of conditions of a browser-based upload using an HTML form (POST Object).
+type PostPolicy struct {
+	Bucket string
+	// KeyPrefix restricts keys of uploaded objects. An empty value allows any key.
+	KeyPrefix string
+	// MinContentLength and MaxContentLength restrict the size of uploaded files if MaxContentLength is positive.
+	MinContentLength int64
+	MaxContentLength int64
+	// ContentType requires the exact Content-Type, ContentTypePrefix requires the Content-Type to start with it.
+	ContentType       string
+	ContentTypePrefix string
+	// SuccessActionStatus (e.g., "201") or SuccessActionRedirect specifies the response of a successful upload.
+	SuccessActionStatus   string
+	SuccessActionRedirect string
+	Expiration            time.Time
+}
+
+// PostForm is a signed policy and form fields for a browser-based upload.
+type PostForm struct {
+	URL string `json:"url"`
+	// Fields are form fields to be sent before the file field, including the policy and the signature.
+	Fields map[string]string `json:"fields"`
+}
+
+// Validate checks the conditions of the policy.
+func (p *PostPolicy) Validate() error {
+	if p.Bucket == "" {
+		return errors.New("invalid post policy: bucket is required")
+	}
+	if p.Expiration.IsZero() || !p.Expiration.After(time.Now()) {
+		return fmt.Errorf("invalid post policy: expiration must be in the future: %v", p.Expiration)
+	}
+	if p.MinContentLength < 0 || (p.MaxContentLength > 0 && p.MinContentLength > p.MaxContentLength) {
+		return fmt.Errorf("invalid post policy: invalid content-length-range: %d-%d", p.MinContentLength, p.MaxContentLength)
+	}
+	if p.ContentType != "" && p.ContentTypePrefix != "" {
+		return errors.New("invalid post policy: ContentType and ContentTypePrefix cannot be specified together")
+	}
+	if p.SuccessActionStatus != "" && p.SuccessActionRedirect != "" {
+		return errors.New("invalid post policy: SuccessActionStatus and SuccessActionRedirect cannot be specified together")
+	}
+	switch p.SuccessActionStatus {
+	case "", "200", "201", "204":
+	default:
+		return fmt.Errorf("invalid post policy: invalid success_action_status: %q", p.SuccessActionStatus)
+	}
+	return nil
+}
+
+// Document returns the policy document in JSON.
+func (p *PostPolicy) Document() ([]byte, error) {
+	conditions := []interface{}{
+		map[string]string{"bucket": p.Bucket},
+		[]string{"starts-with", "$key", p.KeyPrefix},
+	}
+	if p.MaxContentLength > 0 {
+		conditions = append(conditions, []interface{}{"content-length-range", p.MinContentLength, p.MaxContentLength})
+	}
+	if p.ContentType != "" {
+		conditions = append(conditions, map[string]string{"Content-Type": p.ContentType})
+	} else if p.ContentTypePrefix != "" {
+		conditions = append(conditions, []string{"starts-with", "$Content-Type", p.ContentTypePrefix})
+	}
+	if p.SuccessActionStatus != "" {
+		conditions = append(conditions, map[string]string{"success_action_status": p.SuccessActionStatus})
+	}
+	if p.SuccessActionRedirect != "" {
+		conditions = append(conditions, map[string]string{"success_action_redirect": p.SuccessActionRedirect})
+	}
+	return json.Marshal(map[string]interface{}{
+		"expiration": p.Expiration.UTC().Format("2006-01-02T15:04:05.000Z"),
+		"conditions": conditions,
+	})
+}
+
+// PresignPostPolicy signs the policy with the secret access key and returns form fields for the upload.
+// The "key" field has "${filename}" after the prefix, which is replaced with the name of the uploaded file.
+func (cli *DefaultStorageClient) PresignPostPolicy(p *PostPolicy) (form *PostForm, err error) {
+	accessKeyID, secretAccessKey, err := cli.accessKeys()
+	if err != nil {
+		return nil, err
+	}
+	if accessKeyID == "" || secretAccessKey == "" {
+		return nil, errors.New("please check your access_key_id and secret_access_key, and try again")
+	}
+	signer, err := cli.signer()
+	if err != nil {
+		return nil, err
+	}
+	v2, ok := signer.(*V2Signer)
+	if !ok {
+		return nil, errors.New("post policy is supported only with signature version 2")
+	}
+	if err = p.Validate(); err != nil {
+		return nil, err
+	}
+	doc, err := p.Document()
+	if err != nil {
+		return nil, err
+	}
+	if cli.env.Debug {
+		cli.logf(levelDebug, "Post Policy = %s", doc)
+	}
+	policy := base64.StdEncoding.EncodeToString(doc)
+	form = &PostForm{
+		URL: cli.Config.buildURL(p.Bucket, "", nil) + "/",
+		Fields: map[string]string{
+			"key":       p.KeyPrefix + "${filename}",
+			"policy":    policy,
+			"signature": v2.hmacSHA1(policy),
+		},
+	}
+	form.Fields[v2.Vendor+"AccessKeyId"] = v2.AccessKeyID
+	if p.ContentType != "" {
+		form.Fields["Content-Type"] = p.ContentType
+	}
+	if p.SuccessActionStatus != "" {
+		form.Fields["success_action_status"] = p.SuccessActionStatus
+	}
+	if p.SuccessActionRedirect != "" {
+		form.Fields["success_action_redirect"] = p.SuccessActionRedirect
+	}
+	return form, nil
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestPresignPostPolicy(t *testing.T) {
+	client := newMock()
+	policy := &PostPolicy{
+		Bucket:              "mybucket",
+		KeyPrefix:           "uploads/",
+		MaxContentLength:    1048576,
+		ContentTypePrefix:   "image/",
+		SuccessActionStatus: "201",
+		Expiration:          time.Now().Add(time.Hour),
+	}
+	form, err := client.PresignPostPolicy(policy)
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	assertEquals(t, "The form URL is invalid.", form.URL, "https://storage-dag.iijgio.com/mybucket/")
+	assertEquals(t, "Should set the key field.", form.Fields["key"], "uploads/${filename}")
+	assertEquals(t, "Should set the access key id.", form.Fields["IIJGIOAccessKeyId"], "SAMPLE00000000000000")
+	assertEquals(t, "Should set the success_action_status.", form.Fields["success_action_status"], "201")
+
+	mac := hmac.New(sha1.New, []byte("Sample0000000000000000000000000000000000"))
+	mac.Write([]byte(form.Fields["policy"]))
+	assertEquals(t, "The signature is invalid.", form.Fields["signature"], base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+	raw, err := base64.StdEncoding.DecodeString(form.Fields["policy"])
+	assertEquals(t, "The policy should be encoded in base64.", err, nil)
+	var doc struct {
+		Expiration string            `json:"expiration"`
+		Conditions []json.RawMessage `json:"conditions"`
+	}
+	assertEquals(t, "The policy should be JSON.", json.Unmarshal(raw, &doc), nil)
+	if _, err := time.Parse(time.RFC3339, doc.Expiration); err != nil {
+		t.Errorf("The expiration is invalid. %v", doc.Expiration)
+	}
+	var conditions []string
+	for _, c := range doc.Conditions {
+		conditions = append(conditions, string(c))
+	}
+	assertEquals(t, "The conditions are invalid.", len(conditions), 5)
+	assertEquals(t, "The bucket condition is invalid.", conditions[0], `{"bucket":"mybucket"}`)
+	assertEquals(t, "The key condition is invalid.", conditions[1], `["starts-with","$key","uploads/"]`)
+	assertEquals(t, "The content-length-range condition is invalid.", conditions[2], `["content-length-range",0,1048576]`)
+	assertEquals(t, "The Content-Type condition is invalid.", conditions[3], `["starts-with","$Content-Type","image/"]`)
+	assertEquals(t, "The success_action_status condition is invalid.", conditions[4], `{"success_action_status":"201"}`)
+}
+
+func TestValidatePostPolicy(t *testing.T) {
+	expiration := time.Now().Add(time.Hour)
+	for _, p := range []*PostPolicy{
+		{Expiration: expiration},
+		{Bucket: "mybucket"},
+		{Bucket: "mybucket", Expiration: time.Now().Add(-time.Minute)},
+		{Bucket: "mybucket", Expiration: expiration, MinContentLength: 10, MaxContentLength: 1},
+		{Bucket: "mybucket", Expiration: expiration, ContentType: "image/png", ContentTypePrefix: "image/"},
+		{Bucket: "mybucket", Expiration: expiration, SuccessActionStatus: "302"},
+	} {
+		if err := p.Validate(); err == nil {
+			t.Errorf("Should reject an invalid policy. %v", p)
+		}
+	}
+}
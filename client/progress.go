@@ -0,0 +1,178 @@
+package client
+
+import (
+	"io"
+	"sync"
+)
+
+// ProgressEventType is a type of ProgressEvent.
+type ProgressEventType int
+
+// Types of ProgressEvent.
+const (
+	// TransferStarted is sent when a transfer of an object starts.
+	TransferStarted ProgressEventType = iota
+	// BytesTransferred is sent when bytes of an object are transferred.
+	// Bytes is negative when a request of a part is retried and the part is transferred again from the beginning.
+	BytesTransferred
+	// PartCompleted is sent when a part (or an object transferred with a single request) is transferred.
+	PartCompleted
+	// PartSkipped is sent when a part has already been transferred (e.g., a resumed upload or download).
+	PartSkipped
+	// TransferCompleted is sent when a transfer of an object succeeds.
+	TransferCompleted
+	// TransferFailed is sent when a transfer of an object fails.
+	TransferFailed
+)
+
+var progressEventTypeNames = map[ProgressEventType]string{
+	TransferStarted:   "TransferStarted",
+	BytesTransferred:  "BytesTransferred",
+	PartCompleted:     "PartCompleted",
+	PartSkipped:       "PartSkipped",
+	TransferCompleted: "TransferCompleted",
+	TransferFailed:    "TransferFailed",
+}
+
+func (t ProgressEventType) String() string {
+	return progressEventTypeNames[t]
+}
+
+// ProgressEvent is a progress of a transfer of an object by the high level APIs.
+type ProgressEvent struct {
+	Type   ProgressEventType
+	Bucket string
+	Key    string
+	// Size is the size of the object, or -1 if it is unknown (Upload from an io.Reader).
+	Size int64
+	// PartNumber is the number of the part, or 0 for an object transferred with a single request.
+	PartNumber int
+	// Bytes is the number of bytes transferred (BytesTransferred), or the size of the part (PartCompleted, PartSkipped).
+	Bytes int64
+	// Err is the error of TransferFailed.
+	Err error
+}
+
+// ProgressListener receives the progress of the transfers of the high level APIs
+// (Upload, UploadFile, ResumeUploadFile, DownloadFile and ResumeDownloadFile).
+// ProgressChanged is called from the goroutines of the concurrent part transfers, so it must be safe for concurrent use.
+type ProgressListener interface {
+	ProgressChanged(event ProgressEvent)
+}
+
+// ProgressListenerFunc is an adapter to use a function as a ProgressListener.
+type ProgressListenerFunc func(event ProgressEvent)
+
+// ProgressChanged calls f(event).
+func (f ProgressListenerFunc) ProgressChanged(event ProgressEvent) {
+	f(event)
+}
+
+// transferProgress reports the progress of a transfer of an object. A nil *transferProgress reports nothing.
+type transferProgress struct {
+	listener ProgressListener
+	bucket   string
+	key      string
+	size     int64
+}
+
+// newTransferProgress reports the start of a transfer to the ProgressListener of the client.
+// It returns nil if the client has no ProgressListener.
+func (cli *DefaultStorageClient) newTransferProgress(bucket, key string, size int64) *transferProgress {
+	if cli.Progress == nil {
+		return nil
+	}
+	p := &transferProgress{listener: cli.Progress, bucket: bucket, key: key, size: size}
+	p.send(ProgressEvent{Type: TransferStarted})
+	return p
+}
+
+func (p *transferProgress) send(event ProgressEvent) {
+	event.Bucket, event.Key, event.Size = p.bucket, p.key, p.size
+	p.listener.ProgressChanged(event)
+}
+
+// done reports the end of the transfer.
+func (p *transferProgress) done(err error) {
+	if p == nil {
+		return
+	}
+	if err != nil {
+		p.send(ProgressEvent{Type: TransferFailed, Err: err})
+		return
+	}
+	p.send(ProgressEvent{Type: TransferCompleted})
+}
+
+// part returns a partProgress of the part. num is 0 for an object transferred with a single request.
+func (p *transferProgress) part(num int) *partProgress {
+	if p == nil {
+		return nil
+	}
+	return &partProgress{t: p, num: num}
+}
+
+// skip reports the part which has already been transferred.
+func (p *transferProgress) skip(num int, n int64) {
+	if p == nil {
+		return
+	}
+	p.send(ProgressEvent{Type: PartSkipped, PartNumber: num, Bytes: n})
+}
+
+// partProgress reports the bytes of a part. A nil *partProgress reports nothing.
+type partProgress struct {
+	t   *transferProgress
+	num int
+	mu  sync.Mutex
+	n   int64
+}
+
+// reader returns a reader which reports the bytes read from r.
+// The bytes reported by the previous reader (i.e., the previous attempt of the request) are taken back.
+func (p *partProgress) reader(r io.Reader) io.Reader {
+	if p == nil {
+		return r
+	}
+	p.mu.Lock()
+	n := p.n
+	p.n = 0
+	p.mu.Unlock()
+	if n > 0 {
+		p.t.send(ProgressEvent{Type: BytesTransferred, PartNumber: p.num, Bytes: -n})
+	}
+	return &progressReader{r: r, p: p}
+}
+
+func (p *partProgress) add(n int64) {
+	p.mu.Lock()
+	p.n += n
+	p.mu.Unlock()
+	p.t.send(ProgressEvent{Type: BytesTransferred, PartNumber: p.num, Bytes: n})
+}
+
+// completed reports the end of the transfer of the part.
+func (p *partProgress) completed() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	n := p.n
+	p.mu.Unlock()
+	p.t.send(ProgressEvent{Type: PartCompleted, PartNumber: p.num, Bytes: n})
+}
+
+// progressReader reports the bytes read from the underlying io.Reader
+type progressReader struct {
+	r io.Reader
+	p *partProgress
+}
+
+// Read reads bytes from the underlying io.Reader and reports them
+func (r *progressReader) Read(b []byte) (n int, err error) {
+	n, err = r.r.Read(b)
+	if n > 0 {
+		r.p.add(int64(n))
+	}
+	return
+}
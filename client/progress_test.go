@@ -0,0 +1,97 @@
+package client
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+)
+
+// progressRecorder records the progress events.
+type progressRecorder struct {
+	mu     sync.Mutex
+	events []ProgressEvent
+}
+
+func (r *progressRecorder) ProgressChanged(event ProgressEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// sum returns the number of the events and the sum of the bytes of the type.
+func (r *progressRecorder) sum(t ProgressEventType) (count int, bytes int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range r.events {
+		if e.Type == t {
+			count++
+			bytes += e.Bytes
+		}
+	}
+	return
+}
+
+func TestUploadFileProgress(t *testing.T) {
+	client, server := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		switch {
+		case r.Method == "POST" && r.URL.Query().Get("uploadId") == "":
+			w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>mybucket</Bucket><Key>foo</Key><UploadId>1</UploadId></InitiateMultipartUploadResult>`))
+		case r.Method == "POST":
+			w.Write([]byte(`<CompleteMultipartUploadResult><Bucket>mybucket</Bucket><Key>foo</Key></CompleteMultipartUploadResult>`))
+		}
+	})
+	defer server.Close()
+	client.Config.MultipartChunkSize = 4
+	client.env.Concurrency = 2
+	recorder := new(progressRecorder)
+	fd, _ := ioutil.TempFile("", "dagtools")
+	defer os.Remove(fd.Name())
+	defer fd.Close()
+	fd.WriteString("0123456789")
+	if err := client.WithProgress(recorder).UploadFile("mybucket", "foo", fd, nil); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "Should report the start.", recorder.events[0].Type, TransferStarted)
+	assertEquals(t, "Should report the size.", recorder.events[0].Size, int64(10))
+	_, transferred := recorder.sum(BytesTransferred)
+	assertEquals(t, "Should report the bytes.", transferred, int64(10))
+	parts, partBytes := recorder.sum(PartCompleted)
+	assertEquals(t, "Should report the parts.", parts, 3)
+	assertEquals(t, "Should report the bytes of the parts.", partBytes, int64(10))
+	assertEquals(t, "Should report the end.", recorder.events[len(recorder.events)-1].Type, TransferCompleted)
+}
+
+func TestResumeDownloadFileProgress(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	client.Config.MultipartChunkSize = 4
+	mock.EXPECT().Do(gomock.Any()).Return(&http.Response{Body: NewEmptyBody(), StatusCode: 200, ContentLength: 10, Header: http.Header{"Etag": {`"abc"`}}}, nil)
+	mock.EXPECT().Do(rangeMatcher("bytes=8-9")).Return(&http.Response{
+		Body:       NewBodyWithString("89"),
+		StatusCode: 206,
+		Header:     http.Header{"Content-Range": {"bytes 8-9/10"}},
+	}, nil)
+	fd, _ := ioutil.TempFile("", "dagtools")
+	defer os.Remove(fd.Name())
+	defer os.Remove(DownloadStateFilename(fd.Name()))
+	defer fd.Close()
+	fd.WriteString("01234567")
+	summary := &ObjectSummary{ETag: `"abc"`, Size: 10}
+	state := newDownloadState("mybucket", "foo", summary, 4)
+	state.Parts = []int{1, 2}
+	state.save(DownloadStateFilename(fd.Name()))
+	recorder := new(progressRecorder)
+	if err := client.WithProgress(recorder).ResumeDownloadFile("mybucket", "foo", fd); err != nil {
+		t.Fatal(err)
+	}
+	skipped, skippedBytes := recorder.sum(PartSkipped)
+	assertEquals(t, "Should report the downloaded parts.", skipped, 2)
+	assertEquals(t, "Should report the bytes of the downloaded parts.", skippedBytes, int64(8))
+	_, transferred := recorder.sum(BytesTransferred)
+	assertEquals(t, "Should report the bytes.", transferred, int64(2))
+	assertEquals(t, "Should report the end.", recorder.events[len(recorder.events)-1].Type, TransferCompleted)
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/iij/dagtools/env"
+)
+
+// maxRateLimitChunk is the maximum number of bytes passed by a read of a rate limited reader.
+const maxRateLimitChunk = 32 * 1024
+
+// RateLimiter limits the bytes per second shared by concurrent transfers.
+type RateLimiter struct {
+	rate int64
+	mu   sync.Mutex
+	// next is the time when the next bytes are allowed to pass.
+	next time.Time
+}
+
+// NewRateLimiter returns a RateLimiter of bytesPerSec. If bytesPerSec is 0 or less, it returns nil (unlimited).
+func NewRateLimiter(bytesPerSec int64) *RateLimiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	return &RateLimiter{rate: bytesPerSec}
+}
+
+// Rate returns the limit in bytes per second.
+func (l *RateLimiter) Rate() int64 {
+	if l == nil {
+		return 0
+	}
+	return l.rate
+}
+
+// WaitN blocks until n bytes are allowed to pass, or the context is done.
+func (l *RateLimiter) WaitN(ctx context.Context, n int) error {
+	if l == nil || n <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(n) * time.Second / time.Duration(l.rate))
+	l.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// chunkSize returns the maximum number of bytes of a read.
+func (l *RateLimiter) chunkSize() int {
+	n := l.rate / 10
+	if n < 1 {
+		n = 1
+	}
+	if n > maxRateLimitChunk {
+		n = maxRateLimitChunk
+	}
+	return int(n)
+}
+
+// rateLimitedReadCloser reads from the underlying io.ReadCloser within the limit of the RateLimiter
+type rateLimitedReadCloser struct {
+	rc  io.ReadCloser
+	l   *RateLimiter
+	ctx context.Context
+}
+
+// Read reads bytes and waits until they are allowed to pass
+func (r *rateLimitedReadCloser) Read(p []byte) (n int, err error) {
+	if size := r.l.chunkSize(); len(p) > size {
+		p = p[:size]
+	}
+	n, err = r.rc.Read(p)
+	if werr := r.l.WaitN(r.ctx, n); werr != nil {
+		return n, werr
+	}
+	return
+}
+
+// Close closes the underlying io.ReadCloser
+func (r *rateLimitedReadCloser) Close() error {
+	return r.rc.Close()
+}
+
+// ParseRate parses a rate in bytes per second with an optional suffix K, M, G or T (e.g., "512K", "20M").
+// The suffixes are multiples of 1024.
+func ParseRate(s string) (int64, error) {
+	return env.ParseRate(s)
+}
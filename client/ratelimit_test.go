@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	for s, expected := range map[string]int64{"0": 0, "1024": 1024, "512K": 512 * 1024, "20M": 20 * 1024 * 1024, "1g": 1 << 30} {
+		if n, err := ParseRate(s); err != nil || n != expected {
+			t.Errorf("ParseRate(%q) should be %d: %d, %v", s, expected, n, err)
+		}
+	}
+	for _, s := range []string{"", "M", "20X", "-1", "1.5M"} {
+		if _, err := ParseRate(s); err == nil {
+			t.Errorf("ParseRate(%q) should fail.", s)
+		}
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	l := NewRateLimiter(1000)
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		l.WaitN(ctx, 100)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Should wait for the limit: %v", elapsed)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	l.WaitN(ctx, 1000)
+	if err := l.WaitN(ctx, 1); err != context.Canceled {
+		t.Errorf("Should stop waiting when the context is canceled: %v", err)
+	}
+	if NewRateLimiter(0) != nil {
+		t.Error("A rate limiter of 0 should be unlimited (nil).")
+	}
+}
+
+func TestUploadRateLimit(t *testing.T) {
+	client, server := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+	})
+	defer server.Close()
+	fd, _ := ioutil.TempFile("", "dagtools")
+	defer os.Remove(fd.Name())
+	defer fd.Close()
+	fd.WriteString(strings.Repeat("0", 4000))
+	cli := client.WithRateLimit(10000, 0)
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if err := cli.PutObject("mybucket", "foo", fd, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 8000 bytes at 10000 bytes/sec, the first 1000 bytes pass without waiting
+	if elapsed := time.Since(start); elapsed < 600*time.Millisecond {
+		t.Errorf("Should limit the upload rate: %v", elapsed)
+	}
+	if client.uploadLimiter != nil {
+		t.Error("WithRateLimit should not change the original client.")
+	}
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// DefaultRetryOn is a list of HTTP status codes to be retried by default.
+	DefaultRetryOn = []int{429, 500, 502, 503, 504}
+)
+
+// RetryPolicy decides whether a failed request should be retried and how long to wait before the next attempt.
+type RetryPolicy interface {
+	// ShouldRetry returns true if the request failed at the attempt-th (starting from 1) attempt should be retried.
+	// resp is nil if the request failed without an HTTP response (e.g., network error).
+	ShouldRetry(attempt int, resp *http.Response, err error) bool
+	// Backoff returns a duration to wait before the next attempt.
+	Backoff(attempt int, resp *http.Response) time.Duration
+}
+
+// DefaultRetryPolicy retries network errors and the specified HTTP statuses with exponential backoff and jitter.
+type DefaultRetryPolicy struct {
+	// MaxRetry is the maximum number of retries.
+	MaxRetry int
+	// Interval is the base interval of the backoff.
+	Interval time.Duration
+	// MaxBackoff is the upper limit of the backoff (includes Retry-After).
+	MaxBackoff time.Duration
+	// RetryOn is a set of HTTP status codes to be retried.
+	RetryOn map[int]bool
+}
+
+// NewDefaultRetryPolicy returns a DefaultRetryPolicy.
+func NewDefaultRetryPolicy(maxRetry int, interval, maxBackoff time.Duration, retryOn []int) *DefaultRetryPolicy {
+	p := &DefaultRetryPolicy{
+		MaxRetry:   maxRetry,
+		Interval:   interval,
+		MaxBackoff: maxBackoff,
+		RetryOn:    make(map[int]bool),
+	}
+	for _, code := range retryOn {
+		p.RetryOn[code] = true
+	}
+	return p
+}
+
+// ShouldRetry returns true if the number of retries does not exceed MaxRetry and
+// the request failed with a network error or a status code in RetryOn.
+func (p *DefaultRetryPolicy) ShouldRetry(attempt int, resp *http.Response, err error) bool {
+	if attempt > p.MaxRetry {
+		return false
+	}
+	if resp == nil {
+		return err != nil
+	}
+	return p.RetryOn[resp.StatusCode]
+}
+
+// Backoff returns the value of Retry-After header if the response has it.
+// Otherwise, it returns an exponentially increasing interval (Interval * 2^(attempt-1)) with jitter.
+// The duration is limited to MaxBackoff.
+func (p *DefaultRetryPolicy) Backoff(attempt int, resp *http.Response) time.Duration {
+	if d, ok := retryAfter(resp); ok {
+		return p.limit(d)
+	}
+	d := p.Interval
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	d = p.limit(d)
+	if half := int64(d / 2); half > 0 {
+		// equal jitter: [d/2, d)
+		d = time.Duration(half + rand.Int63n(half))
+	}
+	return d
+}
+
+func (p *DefaultRetryPolicy) limit(d time.Duration) time.Duration {
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return d
+}
+
+// retryAfter returns a duration specified by Retry-After header (delay-seconds or HTTP-date).
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	v := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+	if sec, err := strconv.Atoi(v); err == nil && sec >= 0 {
+		return time.Duration(sec) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := t.Sub(time.Now())
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// parseStatusCodes parses a comma separated list of HTTP status codes (e.g., "429,500,503").
+func parseStatusCodes(s string) (codes []int, err error) {
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		code, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return
+}
@@ -0,0 +1,147 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestServerClient(t *testing.T, handler http.HandlerFunc) (*DefaultStorageClient, *httptest.Server) {
+	server := httptest.NewServer(handler)
+	client := newMock()
+	client.Config.Endpoint = strings.TrimPrefix(server.URL, "http://")
+	client.Config.Secure = false
+	client.Config.Retry = 3
+	client.Config.RetryInterval = time.Millisecond
+	client.Config.MaxBackoff = 10 * time.Millisecond
+	client.Config.RetryOn = DefaultRetryOn
+	return client, server
+}
+
+func TestRetryOnServiceUnavailable(t *testing.T) {
+	var count int32
+	client, server := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&count, 1) < 3 {
+			w.WriteHeader(503)
+			return
+		}
+		w.WriteHeader(200)
+	})
+	defer server.Close()
+
+	err := client.PutBucket("mybucket")
+	assertEquals(t, "Should return nil after retries.", err, nil)
+	assertEquals(t, "Should retry until the request succeeds.", atomic.LoadInt32(&count), int32(3))
+}
+
+func TestRetryGiveUp(t *testing.T) {
+	var count int32
+	client, server := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		w.WriteHeader(500)
+	})
+	defer server.Close()
+
+	err := client.DeleteBucket("mybucket")
+	if err == nil {
+		t.Error("Should return an error.")
+	}
+	assertEquals(t, "Should retry at most Retry times.", atomic.LoadInt32(&count), int32(4))
+}
+
+func TestNoRetryOnForbidden(t *testing.T) {
+	var count int32
+	client, server := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(403)
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>SignatureDoesNotMatch</Code></Error>`))
+	})
+	defer server.Close()
+
+	err := client.PutBucket("mybucket")
+	if err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("Should return the error response. %v", err)
+	}
+	assertEquals(t, "Should not retry a non-retryable status.", atomic.LoadInt32(&count), int32(1))
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	var (
+		count int32
+		last  time.Time
+		delay time.Duration
+	)
+	client, server := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&count, 1) == 1 {
+			last = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(429)
+			return
+		}
+		delay = time.Since(last)
+		w.WriteHeader(200)
+	})
+	defer server.Close()
+	client.Config.MaxBackoff = 5 * time.Second
+
+	err := client.PutBucket("mybucket")
+	assertEquals(t, "Should return nil after a retry.", err, nil)
+	if delay < time.Second {
+		t.Errorf("Should wait for Retry-After. %v", delay)
+	}
+}
+
+func TestRetryOnNetworkError(t *testing.T) {
+	client, server := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {})
+	server.Close()
+	var count int32
+	client.RetryPolicy = &countingRetryPolicy{RetryPolicy: NewDefaultRetryPolicy(2, time.Millisecond, time.Millisecond, nil), count: &count}
+
+	if err := client.PutBucket("mybucket"); err == nil {
+		t.Error("Should return an error.")
+	}
+	assertEquals(t, "Should retry network errors.", atomic.LoadInt32(&count), int32(2))
+}
+
+type countingRetryPolicy struct {
+	RetryPolicy
+	count *int32
+}
+
+func (p *countingRetryPolicy) Backoff(attempt int, resp *http.Response) time.Duration {
+	atomic.AddInt32(p.count, 1)
+	return p.RetryPolicy.Backoff(attempt, resp)
+}
+
+func TestDefaultRetryPolicy(t *testing.T) {
+	p := NewDefaultRetryPolicy(2, 100*time.Millisecond, 300*time.Millisecond, []int{503})
+	resp503 := &http.Response{StatusCode: 503, Header: http.Header{}}
+	resp404 := &http.Response{StatusCode: 404, Header: http.Header{}}
+	assertEquals(t, "Should retry a retryable status.", p.ShouldRetry(1, resp503, errors.New("dummy")), true)
+	assertEquals(t, "Should not retry a non-retryable status.", p.ShouldRetry(1, resp404, errors.New("dummy")), false)
+	assertEquals(t, "Should retry a network error.", p.ShouldRetry(2, nil, errors.New("dummy")), true)
+	assertEquals(t, "Should not retry more than MaxRetry.", p.ShouldRetry(3, resp503, errors.New("dummy")), false)
+	for attempt, max := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 5: 300 * time.Millisecond} {
+		d := p.Backoff(attempt, resp503)
+		if d < max/2 || max < d {
+			t.Errorf("Backoff(%d) should be in [%v, %v]. %v", attempt, max/2, max, d)
+		}
+	}
+	resp503.Header.Set("Retry-After", "120")
+	assertEquals(t, "Should limit Retry-After to MaxBackoff.", p.Backoff(1, resp503), 300*time.Millisecond)
+}
+
+func TestParseStatusCodes(t *testing.T) {
+	codes, err := parseStatusCodes(" 429, 503 ,")
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	assertEquals(t, "Should parse status codes.", len(codes), 2)
+	assertEquals(t, "Should parse status codes.", codes[1], 503)
+	if _, err := parseStatusCodes("500,abc"); err == nil {
+		t.Error("Should return an error.")
+	}
+}
@@ -0,0 +1,307 @@
+package client
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureVersion2 is the DAG (and AWS Signature Version 2 compatible) authentication with HMAC-SHA1.
+	SignatureVersion2 = "2"
+	// SignatureVersion4 is the AWS Signature Version 4 authentication with HMAC-SHA256.
+	SignatureVersion4 = "4"
+
+	defaultRegion    = "us-east-1"
+	v4Algorithm      = "AWS4-HMAC-SHA256"
+	v4TimeFormat     = "20060102T150405Z"
+	v4MaxExpires     = 7 * 24 * time.Hour
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// Signer adds authentication information to requests.
+type Signer interface {
+	// Sign sets an Authorization header (and the headers required by the scheme) to the request.
+	Sign(req *http.Request) error
+	// Presign returns an URL of the request authenticated by query string, which is valid until expires.
+	// The headers of the request are signed together, so a request with the URL must have the same values.
+	Presign(req *http.Request, expires time.Time) (string, error)
+}
+
+// NewSigner returns a Signer of the signature version ("2" or "4").
+func NewSigner(version, accessKeyID, secretAccessKey, vendor, region string) (Signer, error) {
+	switch version {
+	case "", SignatureVersion2:
+		return &V2Signer{AccessKeyID: accessKeyID, SecretAccessKey: secretAccessKey, Vendor: vendor}, nil
+	case SignatureVersion4:
+		return &V4Signer{AccessKeyID: accessKeyID, SecretAccessKey: secretAccessKey, Region: region, Service: "s3"}, nil
+	}
+	return nil, fmt.Errorf("unsupported signature version: %q", version)
+}
+
+// V2Signer signs requests with HMAC-SHA1 in the DAG (AWS Signature Version 2 compatible) scheme.
+type V2Signer struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// Vendor is a prefix of the Authorization header and the access key parameter (e.g., "IIJGIO", "AWS").
+	Vendor string
+	// Logger prints the string to sign if not nil.
+	Logger *log.Logger
+}
+
+// Sign sets Date, Content-Type (if empty) and Authorization headers to the request.
+func (s *V2Signer) Sign(req *http.Request) error {
+	date := req.Header.Get("Date")
+	if date == "" {
+		date = time.Now().In(time.UTC).Format(time.RFC1123)
+		req.Header.Set("Date", date)
+	}
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	signature := s.signature(req.Method, date, req.Header, req.URL)
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s:%s", s.Vendor, s.AccessKeyID, signature))
+	return nil
+}
+
+// Presign returns an URL with <Vendor>AccessKeyId, Expires and Signature parameters.
+func (s *V2Signer) Presign(req *http.Request, expires time.Time) (string, error) {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	signature := s.signature(req.Method, exp, req.Header, req.URL)
+	q := url.Values{}
+	q.Set(s.Vendor+"AccessKeyId", s.AccessKeyID)
+	q.Set("Expires", exp)
+	q.Set("Signature", signature)
+	u := *req.URL
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// signature calculates a signature from the StringToSign of the request.
+// date is a value of Date header, or Expires parameter in query string authentication.
+func (s *V2Signer) signature(method, date string, h http.Header, u *url.URL) string {
+	stringToSign := fmt.Sprintf("%s\n%s\n%s\n%s\n%s%s",
+		method,
+		h.Get("Content-MD5"),
+		h.Get("Content-Type"),
+		date,
+		getCanonicalHeaders(h),
+		getCanonicalResource(u))
+	if s.Logger != nil {
+		s.Logger.Printf("StringToSign = %q", stringToSign)
+	}
+	signature := s.hmacSHA1(stringToSign)
+	if s.Logger != nil {
+		s.Logger.Printf("Signature = %q", signature)
+	}
+	return signature
+}
+
+// hmacSHA1 returns a base64 encoded HMAC-SHA1 of the string with the secret access key.
+func (s *V2Signer) hmacSHA1(str string) string {
+	mac := hmac.New(sha1.New, []byte(s.SecretAccessKey))
+	mac.Write([]byte(str))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// V4Signer signs requests with HMAC-SHA256 in the AWS Signature Version 4 scheme.
+// The payload is not signed ("UNSIGNED-PAYLOAD") unless the request has an X-Amz-Content-Sha256 header.
+type V4Signer struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	Region          string
+	Service         string
+	// Logger prints the canonical request and the string to sign if not nil.
+	Logger *log.Logger
+
+	// now returns the current time. It is replaced in tests.
+	now func() time.Time
+}
+
+// Sign sets X-Amz-Date, X-Amz-Content-Sha256 and Authorization headers to the request.
+// All headers of the request except User-Agent are signed.
+func (s *V4Signer) Sign(req *http.Request) error {
+	date := req.Header.Get("X-Amz-Date")
+	if date == "" {
+		date = s.currentTime().Format(v4TimeFormat)
+		req.Header.Set("X-Amz-Date", date)
+	}
+	payloadHash := req.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		payloadHash = unsignedPayload
+		if req.Body == nil || req.Body == http.NoBody {
+			payloadHash = emptyPayloadHash
+		}
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+	signedHeaders, canonicalHeaders := v4CanonicalHeaders(req)
+	signature := s.signature(req.Method, req.URL.Path, v4CanonicalQuery(req.URL.Query()),
+		canonicalHeaders, signedHeaders, payloadHash, date)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		v4Algorithm, s.AccessKeyID, s.scope(date), signedHeaders, signature))
+	return nil
+}
+
+// Presign returns an URL with X-Amz-Algorithm, X-Amz-Credential, X-Amz-Date, X-Amz-Expires,
+// X-Amz-SignedHeaders and X-Amz-Signature parameters. The URL is valid for up to 7 days.
+func (s *V4Signer) Presign(req *http.Request, expires time.Time) (string, error) {
+	now := s.currentTime()
+	ttl := expires.Sub(now) / time.Second * time.Second
+	if ttl <= 0 || ttl > v4MaxExpires {
+		return "", fmt.Errorf("expires must be within %v from now: %v", v4MaxExpires, expires)
+	}
+	date := now.Format(v4TimeFormat)
+	signedHeaders, canonicalHeaders := v4CanonicalHeaders(req)
+	q := req.URL.Query()
+	q.Set("X-Amz-Algorithm", v4Algorithm)
+	q.Set("X-Amz-Credential", s.AccessKeyID+"/"+s.scope(date))
+	q.Set("X-Amz-Date", date)
+	q.Set("X-Amz-Expires", strconv.FormatInt(int64(ttl/time.Second), 10))
+	q.Set("X-Amz-SignedHeaders", signedHeaders)
+	query := v4CanonicalQuery(q)
+	signature := s.signature(req.Method, req.URL.Path, query, canonicalHeaders, signedHeaders, unsignedPayload, date)
+	u := *req.URL
+	u.RawQuery = query + "&X-Amz-Signature=" + signature
+	return u.String(), nil
+}
+
+// signature calculates a signature from the canonical request.
+func (s *V4Signer) signature(method, path, query, canonicalHeaders, signedHeaders, payloadHash, date string) string {
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		method,
+		v4Escape(path, false),
+		query,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	if s.Logger != nil {
+		s.Logger.Printf("CanonicalRequest = %q", canonicalRequest)
+	}
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		v4Algorithm,
+		date,
+		s.scope(date),
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+	if s.Logger != nil {
+		s.Logger.Printf("StringToSign = %q", stringToSign)
+	}
+	key := hmacSHA256([]byte("AWS4"+s.SecretAccessKey), date[:8])
+	for _, v := range []string{s.region(), s.Service, "aws4_request"} {
+		key = hmacSHA256(key, v)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	if s.Logger != nil {
+		s.Logger.Printf("Signature = %q", signature)
+	}
+	return signature
+}
+
+// scope returns the credential scope, e.g., "20130524/us-east-1/s3/aws4_request".
+func (s *V4Signer) scope(date string) string {
+	return strings.Join([]string{date[:8], s.region(), s.Service, "aws4_request"}, "/")
+}
+
+func (s *V4Signer) region() string {
+	if s.Region == "" {
+		return defaultRegion
+	}
+	return s.Region
+}
+
+func (s *V4Signer) currentTime() time.Time {
+	if s.now != nil {
+		return s.now().UTC()
+	}
+	return time.Now().UTC()
+}
+
+// v4CanonicalHeaders returns the signed header names and the canonical headers of the request.
+// The Host header is always signed, and Authorization and User-Agent headers are never signed.
+func v4CanonicalHeaders(req *http.Request) (signedHeaders, canonicalHeaders string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for key, values := range req.Header {
+		_key := strings.ToLower(strings.TrimSpace(key))
+		if _key == "authorization" || _key == "user-agent" {
+			continue
+		}
+		var _values []string
+		for i := range values {
+			_values = append(_values, strings.Join(strings.Fields(values[i]), " "))
+		}
+		headers[_key] = strings.Join(_values, ",")
+	}
+	var names []string
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b []string
+	for _, name := range names {
+		b = append(b, name+":"+headers[name]+"\n")
+	}
+	return strings.Join(names, ";"), strings.Join(b, "")
+}
+
+// v4CanonicalQuery returns the query string sorted by the encoded names, e.g., "acl=&max-keys=2&prefix=J".
+func v4CanonicalQuery(q url.Values) string {
+	var params [][2]string
+	for k, values := range q {
+		for _, v := range values {
+			params = append(params, [2]string{v4Escape(k, true), v4Escape(v, true)})
+		}
+	}
+	sort.Slice(params, func(i, j int) bool {
+		if params[i][0] != params[j][0] {
+			return params[i][0] < params[j][0]
+		}
+		return params[i][1] < params[j][1]
+	})
+	var b []string
+	for _, p := range params {
+		b = append(b, p[0]+"="+p[1])
+	}
+	return strings.Join(b, "&")
+}
+
+// v4Escape percent-encodes all characters except the unreserved characters (A-Z, a-z, 0-9, '-', '.', '_', '~').
+// A slash is also kept if encodeSlash is false.
+func v4Escape(s string, encodeSlash bool) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '.' || c == '_' || c == '~' || (c == '/' && !encodeSlash) {
+			b = append(b, c)
+		} else {
+			b = append(b, fmt.Sprintf("%%%02X", c)...)
+		}
+	}
+	return string(b)
+}
+
+func hmacSHA256(key []byte, s string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(s))
+	return mac.Sum(nil)
+}
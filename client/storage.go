@@ -3,9 +3,8 @@ package client
 import (
 	"bufio"
 	"bytes"
-	"crypto/hmac"
+	"context"
 	"crypto/md5"
-	"crypto/sha1"
 	"crypto/tls"
 	"encoding/base64"
 	"encoding/xml"
@@ -15,6 +14,7 @@ import (
 	"io/ioutil"
 	"log"
 	"math"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -32,7 +32,12 @@ const (
 	bufferSize                int64 = 4096
 	defaultMultipartChunkSize int64 = 1073741824
 	defaultRetry              int   = 2
-	defaultRetryInterval      int64 = 3000 // 3 sec
+	defaultRetryInterval      int64 = 3000  // 3 sec
+	defaultMaxBackoff         int64 = 30000 // 30 sec
+	defaultMaxIdleConns       int   = 100
+	defaultIdleConnTimeout    int64 = 90000 // 90 sec
+	defaultDialTimeout        int64 = 30000 // 30 sec
+	defaultTLSTimeout         int64 = 10000 // 10 sec
 )
 
 var (
@@ -78,18 +83,32 @@ type StorageClient interface {
 	GetBucketPolicy(bucket string) (io.ReadCloser, error)
 	PutBucketPolicy(bucket string, policy io.Reader) error
 	DeleteBucketPolicy(bucket string) error
+	GetBucketAcl(bucket string) (*AccessControlPolicy, error)
+	PutBucketAcl(bucket string, acl *AccessControlPolicy) error
+	PutBucketCannedAcl(bucket, acl string) error
+	GetBucketCors(bucket string) (*CORSConfiguration, error)
+	PutBucketCors(bucket string, cors *CORSConfiguration) error
+	DeleteBucketCors(bucket string) error
+	GetBucketWebsite(bucket string) (*WebsiteConfiguration, error)
+	PutBucketWebsite(bucket string, website *WebsiteConfiguration) error
+	DeleteBucketWebsite(bucket string) error
 
 	// Object API methods
 	ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (*ObjectListing, error)
 	NextListObjects(previous *ObjectListing) (*ObjectListing, error)
 	PutObject(bucket, key string, data *os.File, metadata *ObjectMetadata) error
 	PutObjectAt(bucket, key string, data *os.File, off, length int64, metadata *ObjectMetadata) error
+	CopyObject(srcBucket, srcKey, bucket, key string, metadata *ObjectMetadata) (*CopyObjectResult, error)
 	GetObject(bucket, key string) (io.ReadCloser, error)
+	GetObjectRange(bucket, key string, off, length int64) (io.ReadCloser, *ContentRange, error)
 	DoesObjectExist(bucket, key string) (bool, error)
 	GetObjectSummary(bucket, key string) (*ObjectSummary, error)
 	GetObjectMetadata(bucket, key string) (*Object, error)
 	DeleteObject(bucket, key string) error
 	DeleteMultipleObjects(bucket string, keys []string, quiet bool) (*MultipleDeletionResult, error)
+	GetObjectAcl(bucket, key string) (*AccessControlPolicy, error)
+	PutObjectAcl(bucket, key string, acl *AccessControlPolicy) error
+	PutObjectCannedAcl(bucket, key, acl string) error
 
 	// MultipartUpload API methods
 	ListMultipartUploads(bucket, prefix, keyMarker, uploadIdMarker, delimiter string, maxUploads int) (*MultipartUploadListing, error)
@@ -104,6 +123,11 @@ type StorageClient interface {
 
 	// Utility methods
 	Sign(req *http.Request) error
+	PresignURL(method, bucket, key string, expires time.Time, header http.Header) (string, error)
+	PresignPostPolicy(policy *PostPolicy) (*PostForm, error)
+	WithContext(ctx context.Context) StorageClient
+	WithRateLimit(uploadLimit, downloadLimit int64) StorageClient
+	WithProgress(listener ProgressListener) StorageClient
 
 	// -----------------------
 	// High Level API
@@ -111,14 +135,51 @@ type StorageClient interface {
 	Upload(bucket, key string, data io.Reader, metadata *ObjectMetadata) error
 	UploadFile(bucket, key string, fd *os.File, metadata *ObjectMetadata) error
 	ResumeUploadFile(bucket, key, uploadId string, fd *os.File, metadata *ObjectMetadata) error
+	DownloadFile(bucket, key string, fd *os.File) error
+	ResumeDownloadFile(bucket, key string, fd *os.File) error
 }
 
 // DefaultStorageClient implements StorageClient
 type DefaultStorageClient struct {
-	env        *env.Environment
-	Config     StorageClientConfig
-	Logger     *log.Logger
-	HTTPClient NewHTTPClient
+	env    *env.Environment
+	Config StorageClientConfig
+	Logger *log.Logger
+	// EventLogger records leveled events, e.g., an event of each request with the operation, the status and the duration.
+	// If nil, the events are written to Logger as text.
+	EventLogger env.EventLogger
+	HTTPClient  NewHTTPClient
+	// RetryPolicy decides retries of requests. If nil, DefaultRetryPolicy built from Config is used.
+	RetryPolicy RetryPolicy
+	// Signer signs requests. If nil, a Signer of Config.SignatureVersion is used.
+	Signer Signer
+	// Credentials supplies the keys to sign requests. It is retrieved on the first signed request (or presigning),
+	// not when the client is created. If nil, Config.AccessKeyID and Config.SecretAccessKey are used.
+	Credentials CredentialsProvider
+	// Stats collects the statistics of the requests and the transfers. If nil, they are not collected.
+	Stats *env.Stats
+	// Progress receives the progress of the transfers of the high level APIs. If nil, it is not reported.
+	Progress ProgressListener
+	ctx      context.Context
+	shared   *sharedHTTPClient
+	creds    *sharedCredentials
+	// uploadLimiter and downloadLimiter are shared by the copies of a client (see WithContext).
+	uploadLimiter   *RateLimiter
+	downloadLimiter *RateLimiter
+	// initErr is an error of the configuration or the credentials, which is returned by requests.
+	initErr error
+}
+
+// sharedHTTPClient holds an HTTPClient created once and shared by the copies of a client (see WithContext).
+type sharedHTTPClient struct {
+	once sync.Once
+	c    HTTPClient
+}
+
+// sharedCredentials holds the credentials retrieved once and shared by the copies of a client (see WithContext).
+type sharedCredentials struct {
+	once  sync.Once
+	creds *Credentials
+	err   error
 }
 
 // StorageClientConfig defines parameters for the Client
@@ -127,56 +188,199 @@ type StorageClientConfig struct {
 	AccessKeyID        string
 	SecretAccessKey    string
 	Secure             bool
+	InsecureSkipVerify bool
 	Proxy              string
 	MultipartChunkSize int64
 	TempDir            string
 	Retry              int
 	RetryInterval      time.Duration
+	MaxBackoff         time.Duration
+	RetryOn            []int
 	Vendor             string
 	AbortOnFailure     bool
+	SignatureVersion   string
+	Region             string
+	// UploadLimit and DownloadLimit are the bytes per second of the transfers. 0 is unlimited.
+	UploadLimit   int64
+	DownloadLimit int64
+
+	// HTTP connection settings
+	MaxIdleConns          int
+	MaxIdleConnsPerHost   int
+	IdleConnTimeout       time.Duration
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
 }
 
 // NewStorageClient returns a initiated Client of DAG storage.
 func NewStorageClient(env *env.Environment) (StorageClient, error) {
-	var s *ini.Section
-	if env.Config.HasSection("dagrin") {
-		s = env.Config.Section("dagrin")
-	} else {
-		s = env.Config.Section("storage")
+	s := env.StorageSection()
+	r := &sectionReader{s: s}
+	cli := &DefaultStorageClient{
+		env:         env,
+		Logger:      env.Logger,
+		EventLogger: env.EventLogger,
+		Stats:       env.Stats,
 	}
 	var (
 		endpoint        = s.Get("endpoint", "storage-dag.iijgio.com")
-		accessKeyID     = s.Get("accessKeyId", "")
-		secretAccessKey = s.Get("secretAccessKey", "")
-		secure          = s.GetBool("secure", true)
-		chunkSize       = s.GetInt64("multipartChunkSize", defaultMultipartChunkSize)
-		retry           = s.GetInt("retry", defaultRetry)
-		retryInterval   = s.GetInt64("retryInterval", defaultRetryInterval)
-		abortOnFailure  = s.GetBool("abortOnFailure", true)
+		secure          = r.Bool("secure", true)
+		chunkSize       = r.Int64("multipartChunkSize", defaultMultipartChunkSize)
+		retry           = r.Int("retry", defaultRetry)
+		retryInterval   = r.Int64("retryInterval", defaultRetryInterval)
+		maxBackoff      = r.Int64("maxBackoff", defaultMaxBackoff)
+		retryOn         = DefaultRetryOn
+		maxIdleConns    = r.Int("maxIdleConns", defaultMaxIdleConns)
+		idleConnTimeout = r.Int64("idleConnTimeout", defaultIdleConnTimeout)
+		dialTimeout     = r.Int64("dialTimeout", defaultDialTimeout)
+		tlsTimeout      = r.Int64("tlsHandshakeTimeout", defaultTLSTimeout)
+		headerTimeout   = r.Int64("responseHeaderTimeout", 0)
+		abortOnFailure  = r.Bool("abortOnFailure", true)
+		skipVerify      = r.Bool("insecureSkipVerify", false)
 		vendor          = s.Get("vendor", "IIJGIO")
+		sigVersion      = s.Get("signatureVersion", SignatureVersion2)
+		region          = s.Get("region", defaultRegion)
 		proxy           = env.Config.Get("dagtools", "proxy", "")
 		tempDir         = env.Config.Get("dagtools", "tempDir", os.TempDir())
+		uploadLimit     = r.rate(env.Config, "uploadLimit")
+		downloadLimit   = r.rate(env.Config, "downloadLimit")
 	)
+	// keep as many idle connections as concurrent part transfers
+	maxIdleConnsPerHost := env.Concurrency
+	if maxIdleConnsPerHost < http.DefaultMaxIdleConnsPerHost {
+		maxIdleConnsPerHost = http.DefaultMaxIdleConnsPerHost
+	}
+	maxIdleConnsPerHost = r.Int("maxIdleConnsPerHost", maxIdleConnsPerHost)
+	if v := s.Get("retryOn", ""); v != "" {
+		if codes, err := parseStatusCodes(v); err == nil {
+			retryOn = codes
+		} else {
+			cli.logf(levelWarn, "Invalid retryOn value: %q. The default value is used.", v)
+		}
+	}
+	if sigVersion != SignatureVersion2 && sigVersion != SignatureVersion4 {
+		cli.logf(levelWarn, "Invalid signatureVersion value: %q. The default value is used.", sigVersion)
+		sigVersion = SignatureVersion2
+	}
+	initErr := r.err
+	if initErr != nil {
+		initErr = fmt.Errorf("invalid configuration: %v", initErr)
+		cli.logf(levelError, "%v", initErr)
+	}
 	config := StorageClientConfig{
 		Endpoint:           endpoint,
-		AccessKeyID:        accessKeyID,
-		SecretAccessKey:    secretAccessKey,
 		Secure:             secure,
+		InsecureSkipVerify: skipVerify,
 		Proxy:              proxy,
 		MultipartChunkSize: chunkSize,
 		TempDir:            tempDir,
 		Retry:              retry,
 		RetryInterval:      time.Duration(retryInterval) * time.Millisecond,
+		MaxBackoff:         time.Duration(maxBackoff) * time.Millisecond,
+		RetryOn:            retryOn,
 		AbortOnFailure:     abortOnFailure,
 		Vendor:             vendor,
+		SignatureVersion:   sigVersion,
+		Region:             region,
+		UploadLimit:        uploadLimit,
+		DownloadLimit:      downloadLimit,
+
+		MaxIdleConns:          maxIdleConns,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		IdleConnTimeout:       time.Duration(idleConnTimeout) * time.Millisecond,
+		DialTimeout:           time.Duration(dialTimeout) * time.Millisecond,
+		TLSHandshakeTimeout:   time.Duration(tlsTimeout) * time.Millisecond,
+		ResponseHeaderTimeout: time.Duration(headerTimeout) * time.Millisecond,
+	}
+	cli.Config = config
+	cli.initErr = initErr
+	cli.Credentials = NewDefaultCredentialsProvider(env, s)
+	cli.creds = new(sharedCredentials)
+	cli.HTTPClient = NewDefaultHTTPClient
+	cli.shared = new(sharedHTTPClient)
+	cli.ctx = env.Context
+	cli.uploadLimiter = NewRateLimiter(uploadLimit)
+	cli.downloadLimiter = NewRateLimiter(downloadLimit)
+	return cli, nil
+}
+
+// sectionReader reads typed values of a section and keeps the first error.
+type sectionReader struct {
+	s   *ini.Section
+	err error
+}
+
+func (r *sectionReader) Bool(key string, defaultValue bool) bool {
+	v, err := r.s.Bool(key, defaultValue)
+	r.setErr(err)
+	return v
+}
+
+func (r *sectionReader) Int(key string, defaultValue int) int {
+	v, err := r.s.Int(key, defaultValue)
+	r.setErr(err)
+	return v
+}
+
+func (r *sectionReader) Int64(key string, defaultValue int64) int64 {
+	v, err := r.s.Int64(key, defaultValue)
+	r.setErr(err)
+	return v
+}
+
+// rate returns a rate of the [dagtools] section (e.g., "20M") in bytes per second.
+func (r *sectionReader) rate(config *ini.Config, key string) int64 {
+	value := config.Get("dagtools", key, "0")
+	v, err := ParseRate(value)
+	if err != nil {
+		r.setErr(ini.InvalidValue{Key: "dagtools." + key, Value: value, Type: "rate"})
 	}
-	cli := DefaultStorageClient{
-		Config: config,
-		Logger: env.Logger,
+	return v
+}
+
+func (r *sectionReader) setErr(err error) {
+	if r.err == nil {
+		r.err = err
 	}
-	cli.env = env
-	cli.HTTPClient = NewDefaultHTTPClient
-	return &cli, nil
+}
+
+// WithContext returns a shallow copy of the client with its context changed to ctx.
+// The context controls the entire lifetime of requests, retries and transfers of the returned client.
+func (cli *DefaultStorageClient) WithContext(ctx context.Context) StorageClient {
+	if ctx == nil {
+		panic("nil context")
+	}
+	c := *cli
+	c.ctx = ctx
+	return &c
+}
+
+// WithRateLimit returns a shallow copy of the client with its limits of the transfers changed, in bytes per second.
+// The limits are shared by all the requests of the returned client (and its copies), e.g., the concurrent part uploads.
+// 0 is unlimited.
+func (cli *DefaultStorageClient) WithRateLimit(uploadLimit, downloadLimit int64) StorageClient {
+	c := *cli
+	c.Config.UploadLimit = uploadLimit
+	c.Config.DownloadLimit = downloadLimit
+	c.uploadLimiter = NewRateLimiter(uploadLimit)
+	c.downloadLimiter = NewRateLimiter(downloadLimit)
+	return &c
+}
+
+// WithProgress returns a shallow copy of the client which reports the progress of the transfers to the listener.
+func (cli *DefaultStorageClient) WithProgress(listener ProgressListener) StorageClient {
+	c := *cli
+	c.Progress = listener
+	return &c
+}
+
+// Context returns the context of the client. The default is the background context.
+func (cli *DefaultStorageClient) Context() context.Context {
+	if cli.ctx != nil {
+		return cli.ctx
+	}
+	return context.Background()
 }
 
 // NewHTTPClient define ClientStorageClient instance
@@ -199,17 +403,28 @@ func (client *DefaultHTTPClient) Do(req *http.Request) (resp *http.Response, err
 
 // NewDefaultHTTPClient implements HTTPClient
 func NewDefaultHTTPClient(cli *DefaultStorageClient) HTTPClient {
+	config := cli.Config
+	dialer := &net.Dialer{
+		Timeout:   config.DialTimeout,
+		KeepAlive: 30 * time.Second,
+	}
 	tr := &http.Transport{
-		DisableCompression: true,
+		DisableCompression:    true,
+		DialContext:           dialer.DialContext,
+		MaxIdleConns:          config.MaxIdleConns,
+		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+		IdleConnTimeout:       config.IdleConnTimeout,
+		TLSHandshakeTimeout:   config.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: config.ResponseHeaderTimeout,
 	}
-	if cli.Config.Secure {
-		if cli.env.Config.GetBool("storage", "insecureSkipVerify", false) {
+	if config.Secure {
+		if config.InsecureSkipVerify {
 			tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
 		}
 	}
 	// proxy
-	if cli.Config.Proxy != "" {
-		if proxyURL, err := url.Parse(cli.Config.Proxy); err == nil {
+	if config.Proxy != "" {
+		if proxyURL, err := url.Parse(config.Proxy); err == nil {
 			tr.Proxy = http.ProxyURL(proxyURL)
 		}
 	}
@@ -219,53 +434,69 @@ func NewDefaultHTTPClient(cli *DefaultStorageClient) HTTPClient {
 	return defaultCli
 }
 
+// httpClient returns the HTTPClient of the client. It is created only once per client
+// so that connections (keep-alive and TLS sessions) are reused among requests.
+func (cli *DefaultStorageClient) httpClient() HTTPClient {
+	if cli.shared == nil {
+		return cli.HTTPClient(cli)
+	}
+	cli.shared.once.Do(func() {
+		cli.shared.c = cli.HTTPClient(cli)
+	})
+	return cli.shared.c
+}
+
 // ListBuckets returns list of buckets (GET Service)
 func (cli *DefaultStorageClient) ListBuckets() (listing *BucketListing, err error) {
-	if cli.Config.AccessKeyID == "" {
+	accessKeyID, _, err := cli.accessKeys()
+	if err != nil {
+		return
+	}
+	if accessKeyID == "" {
 		err = errors.New("please check your access_key_id and secret_access_key and try again")
 		return
 	}
 	if cli.env.Debug {
-		cli.env.Logger.Println("Storage REST API Call: GET Service (List Buckets)")
+		cli.logf(levelDebug, "Storage REST API Call: GET Service (List Buckets)")
 	}
 	target := cli.Config.buildURL("", "", nil)
 	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
 		req, err := http.NewRequest("GET", target, nil)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListObjects. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListObjects. reason: %v", err)
 			return nil, err
 		}
 		return req, nil
 	}, &listing)
 	if err != nil {
-		cli.Logger.Println("Failed to execute HTTP request.", err)
+		cli.logf(levelError, "Failed to execute HTTP request. %v", err)
 		return
 	}
-	defer resp.Body.Close()
+	defer closeResponse(resp)
 	return
 }
 
 // PutBucket creates new bucket (PUT Bucket)
 func (cli *DefaultStorageClient) PutBucket(bucket string) error {
 	if cli.env.Debug {
-		cli.env.Logger.Printf("Storage REST API Call: PUT Bucket {bucket: %q}", bucket)
+		cli.logf(levelDebug, "Storage REST API Call: PUT Bucket {bucket: %q}", bucket)
 	}
 	target := cli.Config.buildURL(bucket, "", nil)
 	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
 		req, err := http.NewRequest("PUT", target, nil)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListObjects. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListObjects. reason: %v", err)
 			return nil, err
 		}
 		return req, nil
 	}, nil)
 	if err != nil {
-		cli.Logger.Printf("Failed to execute HTTP request. reason: %s", err)
+		cli.logf(levelError, "Failed to execute HTTP request. reason: %s", err)
 		return err
 	}
-	defer resp.Body.Close()
+	defer closeResponse(resp)
 	if resp.StatusCode != 200 {
-		cli.Logger.Printf("Failed to execute HTTP request.")
+		cli.logf(levelError, "Failed to execute HTTP request.")
 		return err
 	}
 	return nil
@@ -274,22 +505,22 @@ func (cli *DefaultStorageClient) PutBucket(bucket string) error {
 // DeleteBucket removes a bucket (DELETE Bucket)
 func (cli *DefaultStorageClient) DeleteBucket(bucket string) error {
 	if cli.env.Debug {
-		cli.env.Logger.Printf("Storage REST API Call: DELETE Bucket {bucket: %q}", bucket)
+		cli.logf(levelDebug, "Storage REST API Call: DELETE Bucket {bucket: %q}", bucket)
 	}
 	target := cli.Config.buildURL(bucket, "", nil)
 	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
 		req, err := http.NewRequest("DELETE", target, nil)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListObjects. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListObjects. reason: %v", err)
 			return nil, err
 		}
 		return req, nil
 	}, nil)
 	if err != nil {
-		cli.Logger.Printf("Failed to execute HTTP request. reason: %s", err)
+		cli.logf(levelError, "Failed to execute HTTP request. reason: %s", err)
 		return err
 	}
-	defer resp.Body.Close()
+	defer closeResponse(resp)
 	return nil
 }
 
@@ -297,13 +528,13 @@ func (cli *DefaultStorageClient) DeleteBucket(bucket string) error {
 func (cli *DefaultStorageClient) DoesBucketExist(bucket string) (result bool, err error) {
 	result = false
 	if cli.env.Debug {
-		cli.env.Logger.Printf("Storage REST API Call: HEAD Bucket {bucket: %q}", bucket)
+		cli.logf(levelDebug, "Storage REST API Call: HEAD Bucket {bucket: %q}", bucket)
 	}
 	target := cli.Config.buildURL(bucket, "", nil)
 	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
 		req, err := http.NewRequest("HEAD", target, nil)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListObjects. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListObjects. reason: %v", err)
 			return nil, err
 		}
 		return req, nil
@@ -311,31 +542,31 @@ func (cli *DefaultStorageClient) DoesBucketExist(bucket string) (result bool, er
 	if err != nil && resp == nil {
 		return false, err
 	}
-	defer resp.Body.Close()
+	defer closeResponse(resp)
 	sc := resp.StatusCode
 	if sc == 200 || sc == 404 {
 		return sc == 200, nil
 	}
-	cli.Logger.Printf("invalid response [status: %s]", resp.Status)
+	cli.logf(levelError, "invalid response [status: %s]", resp.Status)
 	return false, err
 }
 
 // GetBucketPolicy gets a policy of the specified bucket.
 func (cli *DefaultStorageClient) GetBucketPolicy(bucket string) (policy io.ReadCloser, err error) {
 	if cli.env.Debug {
-		cli.env.Logger.Printf("Storage REST API Call: GET Bucket policy {Bucket: %s}", bucket)
+		cli.logf(levelDebug, "Storage REST API Call: GET Bucket policy {Bucket: %s}", bucket)
 	}
 	target := cli.Config.buildURL(bucket, "", map[string]string{"policy": ""})
 	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
 		req, err := http.NewRequest("GET", target, nil)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListObjects. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListObjects. reason: %v", err)
 			return nil, err
 		}
 		return req, nil
 	}, nil)
 	if err != nil {
-		cli.Logger.Println("Failed to get a bucket policy.", err)
+		cli.logf(levelError, "Failed to get a bucket policy. %v", err)
 		return
 	}
 	switch resp.StatusCode {
@@ -345,7 +576,7 @@ func (cli *DefaultStorageClient) GetBucketPolicy(bucket string) (policy io.ReadC
 		policy = resp.Body
 		return
 	default:
-		cli.Logger.Println("invalid response")
+		cli.logf(levelError, "invalid response")
 		err = errors.New("invalid response")
 	}
 	return
@@ -354,24 +585,24 @@ func (cli *DefaultStorageClient) GetBucketPolicy(bucket string) (policy io.ReadC
 // PutBucketPolicy puts a policy of the specified bucket.
 func (cli *DefaultStorageClient) PutBucketPolicy(bucket string, r io.Reader) error {
 	if cli.env.Debug {
-		cli.env.Logger.Printf("Storage REST API Call: PUT Bucket policy {bucket: %q}", bucket)
+		cli.logf(levelDebug, "Storage REST API Call: PUT Bucket policy {bucket: %q}", bucket)
 	}
 	target := cli.Config.buildURL(bucket, "", map[string]string{"policy": ""})
 	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
 		req, err := http.NewRequest("PUT", target, r)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListObjects. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListObjects. reason: %v", err)
 			return nil, err
 		}
 		return req, nil
 	}, nil)
 	if err != nil {
-		cli.Logger.Println("Failed to put a bucket policy.", err)
+		cli.logf(levelError, "Failed to put a bucket policy. %v", err)
 		return err
 	}
-	defer resp.Body.Close()
+	defer closeResponse(resp)
 	if resp.StatusCode != 204 {
-		cli.Logger.Println("invalid response")
+		cli.logf(levelError, "invalid response")
 		return errors.New("invalid response")
 	}
 	return nil
@@ -380,24 +611,200 @@ func (cli *DefaultStorageClient) PutBucketPolicy(bucket string, r io.Reader) err
 // DeleteBucketPolicy deletes a policy of the specified bucket.
 func (cli *DefaultStorageClient) DeleteBucketPolicy(bucket string) error {
 	if cli.env.Debug {
-		cli.env.Logger.Printf("Storage REST API Call: DELETE Bucket policy {bucket: %q}", bucket)
+		cli.logf(levelDebug, "Storage REST API Call: DELETE Bucket policy {bucket: %q}", bucket)
 	}
 	target := cli.Config.buildURL(bucket, "", map[string]string{"policy": ""})
 	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
 		req, err := http.NewRequest("DELETE", target, nil)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListObjects. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListObjects. reason: %v", err)
+			return nil, err
+		}
+		return req, nil
+	}, nil)
+	if err != nil {
+		cli.logf(levelError, "Failed to delete a bucket policy. %v", err)
+		return err
+	}
+	defer closeResponse(resp)
+	if resp.StatusCode != 204 {
+		cli.logf(levelError, "invalid response")
+		return errors.New("invalid response")
+	}
+	return nil
+}
+
+// GetBucketAcl gets an access control list of the specified bucket (GET Bucket acl)
+func (cli *DefaultStorageClient) GetBucketAcl(bucket string) (*AccessControlPolicy, error) {
+	if cli.env.Debug {
+		cli.logf(levelDebug, "Storage REST API Call: GET Bucket acl {bucket: %q}", bucket)
+	}
+	return cli.getAcl(bucket, "")
+}
+
+// PutBucketAcl sets an access control list of the specified bucket (PUT Bucket acl)
+func (cli *DefaultStorageClient) PutBucketAcl(bucket string, acl *AccessControlPolicy) error {
+	if cli.env.Debug {
+		cli.logf(levelDebug, "Storage REST API Call: PUT Bucket acl {bucket: %q}", bucket)
+	}
+	return cli.putAcl(bucket, "", acl, "")
+}
+
+// PutBucketCannedAcl sets a canned ACL (e.g., "public-read") of the specified bucket (PUT Bucket acl)
+func (cli *DefaultStorageClient) PutBucketCannedAcl(bucket, acl string) error {
+	if cli.env.Debug {
+		cli.logf(levelDebug, "Storage REST API Call: PUT Bucket acl {bucket: %q, acl: %q}", bucket, acl)
+	}
+	return cli.putAcl(bucket, "", nil, acl)
+}
+
+// GetBucketCors gets a CORS configuration of the specified bucket (GET Bucket cors)
+func (cli *DefaultStorageClient) GetBucketCors(bucket string) (cors *CORSConfiguration, err error) {
+	if cli.env.Debug {
+		cli.logf(levelDebug, "Storage REST API Call: GET Bucket cors {bucket: %q}", bucket)
+	}
+	target := cli.Config.buildURL(bucket, "", map[string]string{"cors": ""})
+	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
+		req, err := http.NewRequest("GET", target, nil)
+		if err != nil {
+			cli.logf(levelError, "Failed to create a new HTTP request for GetBucketCors. reason: %v", err)
+			return nil, err
+		}
+		return req, nil
+	}, &cors)
+	if err != nil {
+		cli.logf(levelError, "Failed to get a bucket cors configuration. %v", err)
+		return nil, err
+	}
+	defer closeResponse(resp)
+	return
+}
+
+// PutBucketCors sets a CORS configuration of the specified bucket (PUT Bucket cors)
+func (cli *DefaultStorageClient) PutBucketCors(bucket string, cors *CORSConfiguration) error {
+	if cli.env.Debug {
+		cli.logf(levelDebug, "Storage REST API Call: PUT Bucket cors {bucket: %q}", bucket)
+	}
+	body, err := xml.Marshal(cors)
+	if err != nil {
+		return err
+	}
+	sum := md5.Sum(body)
+	target := cli.Config.buildURL(bucket, "", map[string]string{"cors": ""})
+	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
+		req, err := http.NewRequest("PUT", target, bytes.NewReader(body))
+		if err != nil {
+			cli.logf(levelError, "Failed to create a new HTTP request for PutBucketCors. reason: %v", err)
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/xml")
+		req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+		return req, nil
+	}, nil)
+	if err != nil {
+		cli.logf(levelError, "Failed to put a bucket cors configuration. %v", err)
+		return err
+	}
+	defer closeResponse(resp)
+	return nil
+}
+
+// DeleteBucketCors deletes a CORS configuration of the specified bucket (DELETE Bucket cors)
+func (cli *DefaultStorageClient) DeleteBucketCors(bucket string) error {
+	if cli.env.Debug {
+		cli.logf(levelDebug, "Storage REST API Call: DELETE Bucket cors {bucket: %q}", bucket)
+	}
+	target := cli.Config.buildURL(bucket, "", map[string]string{"cors": ""})
+	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
+		req, err := http.NewRequest("DELETE", target, nil)
+		if err != nil {
+			cli.logf(levelError, "Failed to create a new HTTP request for DeleteBucketCors. reason: %v", err)
 			return nil, err
 		}
 		return req, nil
 	}, nil)
 	if err != nil {
-		cli.Logger.Println("Failed to delete a bucket policy.", err)
+		cli.logf(levelError, "Failed to delete a bucket cors configuration. %v", err)
 		return err
 	}
-	defer resp.Body.Close()
+	defer closeResponse(resp)
 	if resp.StatusCode != 204 {
-		cli.Logger.Println("invalid response")
+		cli.logf(levelError, "invalid response")
+		return errors.New("invalid response")
+	}
+	return nil
+}
+
+// GetBucketWebsite gets a website configuration of the specified bucket (GET Bucket website)
+func (cli *DefaultStorageClient) GetBucketWebsite(bucket string) (website *WebsiteConfiguration, err error) {
+	if cli.env.Debug {
+		cli.logf(levelDebug, "Storage REST API Call: GET Bucket website {bucket: %q}", bucket)
+	}
+	target := cli.Config.buildURL(bucket, "", map[string]string{"website": ""})
+	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
+		req, err := http.NewRequest("GET", target, nil)
+		if err != nil {
+			cli.logf(levelError, "Failed to create a new HTTP request for GetBucketWebsite. reason: %v", err)
+			return nil, err
+		}
+		return req, nil
+	}, &website)
+	if err != nil {
+		cli.logf(levelError, "Failed to get a bucket website configuration. %v", err)
+		return nil, err
+	}
+	defer closeResponse(resp)
+	return
+}
+
+// PutBucketWebsite sets a website configuration of the specified bucket (PUT Bucket website)
+func (cli *DefaultStorageClient) PutBucketWebsite(bucket string, website *WebsiteConfiguration) error {
+	if cli.env.Debug {
+		cli.logf(levelDebug, "Storage REST API Call: PUT Bucket website {bucket: %q}", bucket)
+	}
+	body, err := xml.Marshal(website)
+	if err != nil {
+		return err
+	}
+	target := cli.Config.buildURL(bucket, "", map[string]string{"website": ""})
+	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
+		req, err := http.NewRequest("PUT", target, bytes.NewReader(body))
+		if err != nil {
+			cli.logf(levelError, "Failed to create a new HTTP request for PutBucketWebsite. reason: %v", err)
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/xml")
+		return req, nil
+	}, nil)
+	if err != nil {
+		cli.logf(levelError, "Failed to put a bucket website configuration. %v", err)
+		return err
+	}
+	defer closeResponse(resp)
+	return nil
+}
+
+// DeleteBucketWebsite deletes a website configuration of the specified bucket (DELETE Bucket website)
+func (cli *DefaultStorageClient) DeleteBucketWebsite(bucket string) error {
+	if cli.env.Debug {
+		cli.logf(levelDebug, "Storage REST API Call: DELETE Bucket website {bucket: %q}", bucket)
+	}
+	target := cli.Config.buildURL(bucket, "", map[string]string{"website": ""})
+	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
+		req, err := http.NewRequest("DELETE", target, nil)
+		if err != nil {
+			cli.logf(levelError, "Failed to create a new HTTP request for DeleteBucketWebsite. reason: %v", err)
+			return nil, err
+		}
+		return req, nil
+	}, nil)
+	if err != nil {
+		cli.logf(levelError, "Failed to delete a bucket website configuration. %v", err)
+		return err
+	}
+	defer closeResponse(resp)
+	if resp.StatusCode != 204 {
+		cli.logf(levelError, "invalid response")
 		return errors.New("invalid response")
 	}
 	return nil
@@ -406,22 +813,21 @@ func (cli *DefaultStorageClient) DeleteBucketPolicy(bucket string) error {
 // ListObjects returns list of objects (GET Bucket := List Objects)
 func (cli *DefaultStorageClient) ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (listing *ObjectListing, err error) {
 	if cli.env.Debug {
-		cli.env.Logger.Printf("Storage REST API Call: GET Bucket (List Objects) {bucket: %q, prefix: %q, marker: %q, delimiter: %q, maxKeys: %d}",
-			bucket, prefix, marker, delimiter, maxKeys)
+		cli.logf(levelDebug, "Storage REST API Call: GET Bucket (List Objects) {bucket: %q, prefix: %q, marker: %q, delimiter: %q, maxKeys: %d}", bucket, prefix, marker, delimiter, maxKeys)
 	}
 	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
 		req, err := cli.NewListObjectsRequest(bucket, prefix, marker, delimiter, maxKeys)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListObjects. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListObjects. reason: %v", err)
 			return nil, err
 		}
 		return req, nil
 	}, &listing)
 	if err != nil {
-		cli.Logger.Printf("Failed to execute HTTP request. reason: %v\n", err)
+		cli.logf(levelError, "Failed to execute HTTP request. reason: %v", err)
 		return nil, err
 	}
-	defer resp.Body.Close()
+	defer closeResponse(resp)
 	return
 }
 
@@ -464,70 +870,180 @@ func (cli *DefaultStorageClient) PutObject(bucket, key string, f *os.File, metad
 
 // PutObjectAt uploads n bytes from the File starting at byte offset off.
 func (cli *DefaultStorageClient) PutObjectAt(bucket, key string, f *os.File, off, n int64, metadata *ObjectMetadata) error {
+	return cli.putObjectAt(bucket, key, f, off, n, metadata, nil)
+}
+
+// putObjectAt is PutObjectAt which reports the progress of the upload.
+func (cli *DefaultStorageClient) putObjectAt(bucket, key string, f *os.File, off, n int64, metadata *ObjectMetadata, progress *partProgress) error {
 	if cli.env.Debug {
-		cli.env.Logger.Printf("Storage REST API Call: PUT Object {bucket: %q, key: %q}", bucket, key)
+		cli.logf(levelDebug, "Storage REST API Call: PUT Object {bucket: %q, key: %q}", bucket, key)
 	}
 	target := cli.Config.buildURL(bucket, key, nil)
 	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
-		r := io.NewSectionReader(f, off, n)
+		r := progress.reader(io.NewSectionReader(f, off, n))
 		req, err := http.NewRequest("PUT", target, r)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListParts. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListParts. reason: %v", err)
 			return nil, err
 		}
 		if metadata != nil {
 			metadata.SetMetadata(req.Header)
 		}
 		req.ContentLength = n
-		req.Header.Set("Content-Type", GetMimeType(key))
+		if req.Header.Get("Content-Type") == "" {
+			req.Header.Set("Content-Type", GetMimeType(key))
+		}
 		return req, nil
 	}, nil)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
+	defer closeResponse(resp)
 	if resp.StatusCode != 200 {
 		return errors.New("invalid response")
 	}
+	progress.completed()
 	return nil
 }
 
+// CopyObject creates a copy of an object that is already stored (PUT Object - Copy).
+// If metadata is nil, the metadata of the source object is copied, otherwise it is replaced.
+func (cli *DefaultStorageClient) CopyObject(srcBucket, srcKey, bucket, key string, metadata *ObjectMetadata) (res *CopyObjectResult, err error) {
+	if cli.env.Debug {
+		cli.logf(levelDebug, "Storage REST API Call: PUT Object - Copy {source: %q, bucket: %q, key: %q, metadata: %q}", srcBucket+"/"+srcKey, bucket, key, metadata)
+	}
+	target := cli.Config.buildURL(bucket, key, nil)
+	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
+		req, err := http.NewRequest("PUT", target, nil)
+		if err != nil {
+			cli.logf(levelError, "Failed to create a new HTTP request for CopyObject. reason: %v", err)
+			return nil, err
+		}
+		req.Header.Set("x-iijgio-copy-source", "/"+srcBucket+"/"+encodeURL(srcKey))
+		if metadata != nil {
+			req.Header.Set("x-iijgio-metadata-directive", "REPLACE")
+			metadata.SetMetadata(req.Header)
+			if metadata.ContentType == "" {
+				req.Header.Set("Content-Type", GetMimeType(key))
+			}
+		} else {
+			req.Header.Set("x-iijgio-metadata-directive", "COPY")
+		}
+		return req, nil
+	}, nil)
+	if err != nil {
+		cli.logf(levelError, "Failed to copy an object. %v", err)
+		return
+	}
+	defer closeResponse(resp)
+	// the copy request may fail after the response status (200 OK) was sent.
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var e ErrorResponse
+	if xml.Unmarshal(b, &e) == nil {
+		e.ErrorCode = resp.StatusCode
+		cli.logf(levelError, "Failed to copy an object. %v", e)
+		return nil, e
+	}
+	res = new(CopyObjectResult)
+	if err = xml.Unmarshal(b, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 // GetObject downloads an object (GET Object)
 func (cli *DefaultStorageClient) GetObject(bucket, key string) (r io.ReadCloser, err error) {
 	if cli.env.Debug {
-		cli.env.Logger.Printf("Storage REST API Call: GET Object {bucket: %q, key: %q}", bucket, key)
+		cli.logf(levelDebug, "Storage REST API Call: GET Object {bucket: %q, key: %q}", bucket, key)
 	}
 	target := cli.Config.buildURL(bucket, key, nil)
 	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
 		req, err := http.NewRequest("GET", target, nil)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListParts. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListParts. reason: %v", err)
 			return nil, err
 		}
 		return req, nil
 	}, nil)
 	if err != nil {
-		cli.Logger.Println("Failed to execute HTTP request.", err)
+		cli.logf(levelError, "Failed to execute HTTP request. %v", err)
 		return
 	}
 	if resp.StatusCode != 200 {
-		cli.Logger.Println("Failed to execute HTTP request.")
+		cli.logf(levelError, "Failed to execute HTTP request.")
 		return nil, errors.New("invalid response")
 	}
 	r = resp.Body
 	return
 }
 
+// GetObjectRange downloads length bytes of an object starting at byte offset off (GET Object with Range header).
+// If length is 0 or less, it downloads to the end of the object.
+// If off is negative, it downloads the last -off bytes of the object (suffix range).
+// If the server ignores the Range header, the whole object is returned with its range, or an error if its size is unknown.
+func (cli *DefaultStorageClient) GetObjectRange(bucket, key string, off, length int64) (r io.ReadCloser, cr *ContentRange, err error) {
+	var byteRange string
+	switch {
+	case off < 0:
+		byteRange = fmt.Sprintf("bytes=%d", off)
+	case length > 0:
+		byteRange = fmt.Sprintf("bytes=%d-%d", off, off+length-1)
+	default:
+		byteRange = fmt.Sprintf("bytes=%d-", off)
+	}
+	if cli.env.Debug {
+		cli.logf(levelDebug, "Storage REST API Call: GET Object {bucket: %q, key: %q, range: %q}", bucket, key, byteRange)
+	}
+	target := cli.Config.buildURL(bucket, key, nil)
+	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
+		req, err := http.NewRequest("GET", target, nil)
+		if err != nil {
+			cli.logf(levelError, "Failed to create a new HTTP request for GetObjectRange. reason: %v", err)
+			return nil, err
+		}
+		req.Header.Set("Range", byteRange)
+		return req, nil
+	}, nil)
+	if err != nil {
+		cli.logf(levelError, "Failed to execute HTTP request. %v", err)
+		return
+	}
+	switch resp.StatusCode {
+	case 206:
+		if cr, err = ParseContentRange(resp.Header.Get("Content-Range")); err != nil {
+			closeResponse(resp)
+			return nil, nil, err
+		}
+	case 200:
+		// the whole object is returned if the server ignores the Range header.
+		if resp.ContentLength < 0 {
+			closeResponse(resp)
+			cli.logf(levelError, "Failed to get the range of the object: the size of the whole object is unknown.")
+			return nil, nil, errors.New("invalid response: the server ignored the Range header and returned the object of unknown size")
+		}
+		cr = &ContentRange{First: 0, Last: resp.ContentLength - 1, Total: resp.ContentLength}
+	default:
+		closeResponse(resp)
+		cli.logf(levelError, "Failed to execute HTTP request.")
+		return nil, nil, errors.New("invalid response")
+	}
+	r = resp.Body
+	return
+}
+
 // DoesObjectExist returns presence of an object (HEAD Object)
 func (cli *DefaultStorageClient) DoesObjectExist(bucket, key string) (bool, error) {
 	if cli.env.Debug {
-		cli.env.Logger.Printf("Storage REST API Call: HEAD Object {bucket: %q, key: %q}", bucket, key)
+		cli.logf(levelDebug, "Storage REST API Call: HEAD Object {bucket: %q, key: %q}", bucket, key)
 	}
 	target := cli.Config.buildURL(bucket, key, nil)
 	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
 		req, err := http.NewRequest("HEAD", target, nil)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListParts. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListParts. reason: %v", err)
 			return nil, err
 		}
 		return req, nil
@@ -535,25 +1051,25 @@ func (cli *DefaultStorageClient) DoesObjectExist(bucket, key string) (bool, erro
 	if resp == nil && err != nil {
 		return false, err
 	}
-	defer resp.Body.Close()
+	defer closeResponse(resp)
 	sc := resp.StatusCode
 	if sc == 200 || sc == 404 {
 		return sc == 200, nil
 	}
-	cli.Logger.Printf("invalid response [status: %s]", resp.Status)
+	cli.logf(levelError, "invalid response [status: %s]", resp.Status)
 	return false, err
 }
 
 // GetObjectSummary returns summary information of the Object.
 func (cli *DefaultStorageClient) GetObjectSummary(bucket, key string) (os *ObjectSummary, err error) {
 	if cli.env.Debug {
-		cli.env.Logger.Printf("Storage REST API Call: HEAD Object {bucket: %q, key: %q}", bucket, key)
+		cli.logf(levelDebug, "Storage REST API Call: HEAD Object {bucket: %q, key: %q}", bucket, key)
 	}
 	target := cli.Config.buildURL(bucket, key, nil)
 	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
 		req, err := http.NewRequest("HEAD", target, nil)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListParts. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListParts. reason: %v", err)
 			return nil, err
 		}
 		return req, nil
@@ -561,7 +1077,7 @@ func (cli *DefaultStorageClient) GetObjectSummary(bucket, key string) (os *Objec
 	if err != nil {
 		return
 	}
-	defer resp.Body.Close()
+	defer closeResponse(resp)
 	if resp.StatusCode == 200 {
 		lastModified, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
 		return &ObjectSummary{
@@ -577,13 +1093,13 @@ func (cli *DefaultStorageClient) GetObjectSummary(bucket, key string) (os *Objec
 // GetObjectMetadata returns metadata of object. if the object does not exist, return nil.
 func (cli *DefaultStorageClient) GetObjectMetadata(bucket, key string) (o *Object, err error) {
 	if cli.env.Debug {
-		cli.env.Logger.Printf("Storage REST API Call: HEAD Object {bucket: %q, key: %q}", bucket, key)
+		cli.logf(levelDebug, "Storage REST API Call: HEAD Object {bucket: %q, key: %q}", bucket, key)
 	}
 	target := cli.Config.buildURL(bucket, key, nil)
 	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
 		req, err := http.NewRequest("HEAD", target, nil)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListParts. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListParts. reason: %v", err)
 			return nil, err
 		}
 		return req, nil
@@ -591,7 +1107,7 @@ func (cli *DefaultStorageClient) GetObjectMetadata(bucket, key string) (o *Objec
 	if resp == nil && err != nil {
 		return
 	}
-	defer resp.Body.Close()
+	defer closeResponse(resp)
 	if resp.StatusCode == 404 {
 		return nil, nil
 	}
@@ -627,22 +1143,22 @@ func (cli *DefaultStorageClient) GetObjectMetadata(bucket, key string) (o *Objec
 // DeleteObject removes an object (DELETE Object)
 func (cli *DefaultStorageClient) DeleteObject(bucket, key string) (err error) {
 	if cli.env.Debug {
-		cli.env.Logger.Printf("Storage REST API Call: DELETE Object {bucket: %q, key: %q}", bucket, key)
+		cli.logf(levelDebug, "Storage REST API Call: DELETE Object {bucket: %q, key: %q}", bucket, key)
 	}
 	target := cli.Config.buildURL(bucket, key, nil)
 	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
 		req, err := http.NewRequest("DELETE", target, nil)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListParts. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListParts. reason: %v", err)
 			return nil, err
 		}
 		return req, nil
 	}, nil)
 	if err != nil {
-		cli.Logger.Println("Failed to execute HTTP request.", err)
+		cli.logf(levelError, "Failed to execute HTTP request. %v", err)
 		return
 	}
-	defer resp.Body.Close()
+	defer closeResponse(resp)
 	return
 }
 
@@ -653,7 +1169,7 @@ func (cli *DefaultStorageClient) DeleteMultipleObjects(bucket string, keys []str
 		_keys[i] = multipleDeletionKey{Key: key}
 	}
 	if cli.env.Debug {
-		cli.env.Logger.Printf("Storage REST API Call: Delete Multiple Objects {bucket: %q, key: %q, quiet: %v}", bucket, keys, quiet)
+		cli.logf(levelDebug, "Storage REST API Call: Delete Multiple Objects {bucket: %q, key: %q, quiet: %v}", bucket, keys, quiet)
 	}
 	request := multipleDeletionRequest{Quiet: quiet, Keys: _keys}
 	target := cli.Config.buildURL(bucket, "", map[string]string{"delete": ""})
@@ -665,7 +1181,7 @@ func (cli *DefaultStorageClient) DeleteMultipleObjects(bucket string, keys []str
 		r := bytes.NewReader(b)
 		req, err := http.NewRequest("POST", target, r)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListParts. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListParts. reason: %v", err)
 		}
 		req.Header.Set("Content-Type", "text/xml")
 		return req, nil
@@ -673,10 +1189,87 @@ func (cli *DefaultStorageClient) DeleteMultipleObjects(bucket string, keys []str
 	if err != nil {
 		return
 	}
-	defer resp.Body.Close()
+	defer closeResponse(resp)
 	return res, nil
 }
 
+// GetObjectAcl gets an access control list of the specified object (GET Object acl)
+func (cli *DefaultStorageClient) GetObjectAcl(bucket, key string) (*AccessControlPolicy, error) {
+	if cli.env.Debug {
+		cli.logf(levelDebug, "Storage REST API Call: GET Object acl {bucket: %q, key: %q}", bucket, key)
+	}
+	return cli.getAcl(bucket, key)
+}
+
+// PutObjectAcl sets an access control list of the specified object (PUT Object acl)
+func (cli *DefaultStorageClient) PutObjectAcl(bucket, key string, acl *AccessControlPolicy) error {
+	if cli.env.Debug {
+		cli.logf(levelDebug, "Storage REST API Call: PUT Object acl {bucket: %q, key: %q}", bucket, key)
+	}
+	return cli.putAcl(bucket, key, acl, "")
+}
+
+// PutObjectCannedAcl sets a canned ACL (e.g., "public-read") of the specified object (PUT Object acl)
+func (cli *DefaultStorageClient) PutObjectCannedAcl(bucket, key, acl string) error {
+	if cli.env.Debug {
+		cli.logf(levelDebug, "Storage REST API Call: PUT Object acl {bucket: %q, key: %q, acl: %q}", bucket, key, acl)
+	}
+	return cli.putAcl(bucket, key, nil, acl)
+}
+
+func (cli *DefaultStorageClient) getAcl(bucket, key string) (acl *AccessControlPolicy, err error) {
+	target := cli.Config.buildURL(bucket, key, map[string]string{"acl": ""})
+	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
+		req, err := http.NewRequest("GET", target, nil)
+		if err != nil {
+			cli.logf(levelError, "Failed to create a new HTTP request for GetAcl. reason: %v", err)
+			return nil, err
+		}
+		return req, nil
+	}, &acl)
+	if err != nil {
+		cli.logf(levelError, "Failed to get an acl. %v", err)
+		return nil, err
+	}
+	defer closeResponse(resp)
+	return
+}
+
+// putAcl sets either an access control list or a canned ACL (x-iijgio-acl header).
+func (cli *DefaultStorageClient) putAcl(bucket, key string, acl *AccessControlPolicy, canned string) error {
+	var (
+		body []byte
+		err  error
+	)
+	if acl != nil {
+		if body, err = xml.Marshal(acl); err != nil {
+			return err
+		}
+	} else if !IsCannedACL(canned) {
+		return fmt.Errorf("invalid canned acl: %q", canned)
+	}
+	target := cli.Config.buildURL(bucket, key, map[string]string{"acl": ""})
+	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
+		req, err := http.NewRequest("PUT", target, bytes.NewReader(body))
+		if err != nil {
+			cli.logf(levelError, "Failed to create a new HTTP request for PutAcl. reason: %v", err)
+			return nil, err
+		}
+		if acl != nil {
+			req.Header.Set("Content-Type", "application/xml")
+		} else {
+			req.Header.Set("x-iijgio-acl", canned)
+		}
+		return req, nil
+	}, nil)
+	if err != nil {
+		cli.logf(levelError, "Failed to put an acl. %v", err)
+		return err
+	}
+	defer closeResponse(resp)
+	return nil
+}
+
 // ListMultipartUploads returns list of multipart-uploads
 func (cli *DefaultStorageClient) ListMultipartUploads(bucket, prefix, keyMarker, uploadIdMarker, delimiter string, maxUploads int) (listing *MultipartUploadListing, err error) {
 	queries := map[string]string{
@@ -702,16 +1295,16 @@ func (cli *DefaultStorageClient) ListMultipartUploads(bucket, prefix, keyMarker,
 	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
 		req, err := http.NewRequest("GET", target, nil)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListObjects. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListObjects. reason: %v", err)
 			return nil, err
 		}
 		return req, nil
 	}, &listing)
 	if err != nil {
-		cli.Logger.Printf("Failed to execute HTTP request. reason: %v\n", err)
+		cli.logf(levelError, "Failed to execute HTTP request. reason: %v", err)
 		return nil, err
 	}
-	defer resp.Body.Close()
+	defer closeResponse(resp)
 	prefixes := make([]string, 0)
 	for _, cm := range listing.CommonPrefixes {
 		if cm != "" {
@@ -724,7 +1317,7 @@ func (cli *DefaultStorageClient) ListMultipartUploads(bucket, prefix, keyMarker,
 
 // NextListMultipartUploads returns next page of multipart-uploads listing
 func (cli *DefaultStorageClient) NextListMultipartUploads(prev *MultipartUploadListing) (listing *MultipartUploadListing, err error) {
-	if ! prev.IsTruncated {
+	if !prev.IsTruncated {
 		return nil, errors.New("the listing is not truncated")
 	}
 	return cli.ListMultipartUploads(prev.Bucket, prev.Prefix, prev.NextKeyMarker, prev.NextUploadIdMarker, prev.Delimiter, prev.MaxUploads)
@@ -733,13 +1326,13 @@ func (cli *DefaultStorageClient) NextListMultipartUploads(prev *MultipartUploadL
 // InitiateMultipartUpload starts the multipart upload
 func (cli *DefaultStorageClient) InitiateMultipartUpload(bucket, key string, metadata *ObjectMetadata) (res *MultipartUpload, err error) {
 	if cli.env.Debug {
-		cli.env.Logger.Printf("Storage REST API Call: Initiate Multipart Upload {bucket: %q, key: %q, metadata: %q}", bucket, key, metadata)
+		cli.logf(levelDebug, "Storage REST API Call: Initiate Multipart Upload {bucket: %q, key: %q, metadata: %q}", bucket, key, metadata)
 	}
 	target := cli.Config.buildURL(bucket, key, map[string]string{"uploads": ""})
 	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
 		req, err := http.NewRequest("POST", target, nil)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListParts. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListParts. reason: %v", err)
 			return nil, err
 		}
 		if metadata != nil {
@@ -748,43 +1341,67 @@ func (cli *DefaultStorageClient) InitiateMultipartUpload(bucket, key string, met
 		return req, nil
 	}, &res)
 	if err != nil {
-		cli.Logger.Println("Failed to initiate a new multipart upload.", err)
+		cli.logf(levelError, "Failed to initiate a new multipart upload. %v", err)
 		return
 	}
-	defer resp.Body.Close()
+	defer closeResponse(resp)
 	return
 }
 
 // AbortMultipartUpload cancels the multipart upload.
 func (cli *DefaultStorageClient) AbortMultipartUpload(upload *MultipartUpload) error {
 	if cli.env.Debug {
-		cli.env.Logger.Printf("Abort Multipart Upload: %v", upload)
+		cli.logf(levelDebug, "Abort Multipart Upload: %v", upload)
 	}
 	target := cli.Config.buildURL(upload.Bucket, upload.Key, map[string]string{"uploadId": upload.UploadID})
 	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
 		req, err := http.NewRequest("DELETE", target, nil)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListParts. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListParts. reason: %v", err)
 			return nil, err
 		}
 		return req, nil
 	}, nil)
 	if err != nil {
-		cli.Logger.Println(err.Error())
+		cli.logf(levelError, "%v", err.Error())
 		return err
 	}
-	defer resp.Body.Close()
+	defer closeResponse(resp)
 	if resp.StatusCode != 204 {
-		cli.Logger.Printf("Failed to abort the multipart upload. StatusCode: 204 != %v", resp.StatusCode)
+		cli.logf(levelError, "Failed to abort the multipart upload. StatusCode: 204 != %v", resp.StatusCode)
 		err = errors.New("failed to abort the multipart upload")
 	}
 	return nil
 }
 
+// abortMultipartUpload aborts a multipart upload even if the context of the client has been canceled.
+func (cli *DefaultStorageClient) abortMultipartUpload(upload *MultipartUpload) error {
+	return cli.WithContext(context.Background()).AbortMultipartUpload(upload)
+}
+
+// failMultipartUpload aborts the failed multipart upload if abortOnFailure is enabled.
+// Otherwise (or if it could not be aborted), it returns MultipartUploadError to resume the upload.
+func (cli *DefaultStorageClient) failMultipartUpload(upload *MultipartUpload, err error) error {
+	if err == nil {
+		err = errors.New("failed to upload file(s)")
+	}
+	if upload == nil {
+		return err
+	}
+	if cli.Config.AbortOnFailure {
+		aerr := cli.abortMultipartUpload(upload)
+		if aerr == nil {
+			return err
+		}
+		cli.logf(levelError, "Failed to abort the multipart upload (UploadId: %s). %v", upload.UploadID, aerr)
+	}
+	return &MultipartUploadError{Upload: upload, Err: err}
+}
+
 // CompleteMultipartUpload creates a storage object
 func (cli *DefaultStorageClient) CompleteMultipartUpload(upload *MultipartUpload, parts []*Part) (res *CompleteMultipartUploadResult, err error) {
 	if cli.env.Debug {
-		cli.env.Logger.Printf("Storage REST API Call: Complete Multipart Upload {upload: %v, parts: %v}", upload, parts)
+		cli.logf(levelDebug, "Storage REST API Call: Complete Multipart Upload {upload: %v, parts: %v}", upload, parts)
 	}
 	var request = completeMultipartUploadRequest{Parts: parts}
 	target := cli.Config.buildURL(upload.Bucket, upload.Key, map[string]string{"uploadId": upload.UploadID})
@@ -794,16 +1411,16 @@ func (cli *DefaultStorageClient) CompleteMultipartUpload(upload *MultipartUpload
 		req, err := http.NewRequest("POST", target, r)
 		req.Header.Set("Content-Type", "text/xml")
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListParts. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListParts. reason: %v", err)
 			return nil, err
 		}
 		return req, nil
 	}, &res)
 	if err != nil {
-		cli.Logger.Println("Failed to complete the multipart uploads.", err)
+		cli.logf(levelError, "Failed to complete the multipart uploads. %v", err)
 		return
 	}
-	defer resp.Body.Close()
+	defer closeResponse(resp)
 	return
 }
 
@@ -823,21 +1440,21 @@ func (cli *DefaultStorageClient) ListParts(bucket, key, uploadId string, partNum
 	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
 		req, err := http.NewRequest("GET", target, nil)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListParts. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListParts. reason: %v", err)
 			return nil, err
 		}
 		return req, nil
 	}, &listing)
 	if err != nil {
-		cli.Logger.Printf("Failed to execute a HTTP request. reason: %v\n", err)
+		cli.logf(levelError, "Failed to execute a HTTP request. reason: %v", err)
 	}
-	defer resp.Body.Close()
+	defer closeResponse(resp)
 	return listing, err
 }
 
 // NextListParts returns next page of parts
 func (cli *DefaultStorageClient) NextListParts(prev *PartListing) (listing *PartListing, err error) {
-	if ! prev.IsTruncated {
+	if !prev.IsTruncated {
 		return nil, errors.New("the listing is not truncated")
 	}
 	return cli.ListParts(prev.Bucket, prev.Key, prev.UploadId, prev.NextPartNumberMarker, prev.MaxParts)
@@ -845,27 +1462,37 @@ func (cli *DefaultStorageClient) NextListParts(prev *PartListing) (listing *Part
 
 // UploadPart uploads a part of the multipart upload
 func (cli *DefaultStorageClient) UploadPart(upload *MultipartUpload, num int, f *os.File) (p *Part, err error) {
+	return cli.uploadPart(upload, num, f, nil)
+}
+
+// uploadPart is UploadPart which reports the progress of the upload.
+func (cli *DefaultStorageClient) uploadPart(upload *MultipartUpload, num int, f *os.File, progress *partProgress) (p *Part, err error) {
 	stat, err := f.Stat()
 	if err != nil {
 		return
 	}
 	size := stat.Size()
-	return cli.UploadPartAt(upload, num, f, 0, size)
+	return cli.uploadPartAt(upload, num, f, 0, size, progress)
 }
 
 // UploadPartAt uploads n bytes from the File starting at byte offset off.
 func (cli *DefaultStorageClient) UploadPartAt(upload *MultipartUpload, num int, f *os.File, off, n int64) (p *Part, err error) {
+	return cli.uploadPartAt(upload, num, f, off, n, nil)
+}
+
+// uploadPartAt is UploadPartAt which reports the progress of the upload.
+func (cli *DefaultStorageClient) uploadPartAt(upload *MultipartUpload, num int, f *os.File, off, n int64, progress *partProgress) (p *Part, err error) {
 	if cli.env.Debug {
-		cli.env.Logger.Printf("Storage REST API Call: Upload Part {upload: %v, num: %d}", upload, num)
+		cli.logf(levelDebug, "Storage REST API Call: Upload Part {upload: %v, num: %d}", upload, num)
 	}
 	target := cli.Config.buildURL(upload.Bucket, upload.Key,
 		map[string]string{"partNumber": strconv.Itoa(num), "uploadId": upload.UploadID})
 	var r DigestReader
 	resp, err := cli.DoAndRetry(func() (*http.Request, error) {
-		r = DigestReader{r: io.NewSectionReader(f, off, n), h: md5.New()}
+		r = DigestReader{r: progress.reader(io.NewSectionReader(f, off, n)), h: md5.New()}
 		req, err := http.NewRequest("PUT", target, r)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListParts. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListParts. reason: %v", err)
 			return nil, err
 		}
 		req.ContentLength = n
@@ -874,8 +1501,9 @@ func (cli *DefaultStorageClient) UploadPartAt(upload *MultipartUpload, num int,
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	defer closeResponse(resp)
 	p = &Part{PartNumber: num, ETag: fmt.Sprintf(`"%x"`, r.Digest())}
+	progress.completed()
 	return p, nil
 }
 
@@ -883,6 +1511,9 @@ func (cli *DefaultStorageClient) UploadPartAt(upload *MultipartUpload, num int,
 func (cli *DefaultStorageClient) Upload(bucket, key string, r io.Reader, metadata *ObjectMetadata) (err error) {
 	logger := cli.env.Logger
 	logger.Printf("Uploading to %s:%s ...", bucket, key)
+	defer cli.countTransferred(&err)
+	progress := cli.newTransferProgress(bucket, key, -1)
+	defer func() { progress.done(err) }()
 	var (
 		count  int
 		size   int64
@@ -891,24 +1522,35 @@ func (cli *DefaultStorageClient) Upload(bucket, key string, r io.Reader, metadat
 		upload *MultipartUpload
 		parts  = make([]*Part, 1000)
 		num    = 1
+		mu     sync.Mutex
 		ok     = true
 	)
+	fail := func() {
+		mu.Lock()
+		ok = false
+		mu.Unlock()
+	}
 	defer func() {
+		// wait for the upload workers before aborting the upload
+		wg.Wait()
+		// remove the temporary file which has not been passed to an upload worker
+		if out != nil {
+			out.Close()
+			os.Remove(out.Name())
+		}
 		if !ok {
-			if err != nil {
-				if upload != nil && cli.Config.AbortOnFailure {
-					cli.AbortMultipartUpload(upload)
-				}
-				if err == nil {
-					err = errors.New("failed to upload file(s)")
-				}
-			}
+			err = cli.failMultipartUpload(upload, err)
 		}
 	}()
 	buf := make([]byte, bufferSize)
 	uploadChannel := make(chan bool, cli.env.Concurrency)
 	tmpFileWriteChannel := make(chan bool, cli.env.Concurrency+1)
 	for {
+		if err = cli.Context().Err(); err != nil {
+			fail()
+			wg.Wait()
+			return
+		}
 		n, _ := r.Read(buf)
 		if n < 1 {
 			break
@@ -919,11 +1561,14 @@ func (cli *DefaultStorageClient) Upload(bucket, key string, r io.Reader, metadat
 			out, err = ioutil.TempFile(cli.Config.TempDir, "dagtools-")
 			if err != nil {
 				logger.Printf("Failed to create a temporary file.")
+				fail()
+				wg.Wait()
 				return
 			}
 		}
 		if _, err = out.Write(buf[0:n]); err != nil {
-			out.Close()
+			fail()
+			wg.Wait()
 			return err
 		}
 		count++
@@ -932,7 +1577,7 @@ func (cli *DefaultStorageClient) Upload(bucket, key string, r io.Reader, metadat
 			if upload == nil {
 				upload, err = cli.InitiateMultipartUpload(bucket, key, metadata)
 				if err != nil {
-					ok = false
+					fail()
 					return err
 				}
 			}
@@ -945,17 +1590,23 @@ func (cli *DefaultStorageClient) Upload(bucket, key string, r io.Reader, metadat
 					<-tmpFileWriteChannel
 					<-uploadChannel
 				}()
+				if cli.Context().Err() != nil {
+					fail()
+					return
+				}
 				logger.Printf("Uploading a part (File: %v, UploadNumber: %d) ...", filename, num)
 				f, err := os.Open(filename)
 				if err != nil {
 					logger.Printf(err.Error())
-					ok = false
+					fail()
 					return
 				}
 				defer f.Close()
-				part, err := cli.UploadPart(upload, num, f)
+				part, err := cli.uploadPart(upload, num, f, progress.part(num))
 				if err != nil {
 					logger.Printf(err.Error())
+					fail()
+					return
 				}
 				parts[num-1] = part
 				logger.Printf("Finished to write the part file: %v", filename)
@@ -967,26 +1618,35 @@ func (cli *DefaultStorageClient) Upload(bucket, key string, r io.Reader, metadat
 		}
 	}
 	if out != nil {
-		filename := out.Name()
-		defer os.Remove(filename)
 		if upload == nil {
-			f, err := os.Open(filename)
+			f, err := os.Open(out.Name())
 			if err != nil {
 				return err
 			}
-			return cli.PutObject(bucket, key, f, metadata)
+			defer f.Close()
+			fi, err := f.Stat()
+			if err != nil {
+				return err
+			}
+			return cli.putObjectAt(bucket, key, f, 0, fi.Size(), metadata, progress.part(0))
 		}
-		part, err := cli.UploadPart(upload, num, out)
+		part, err := cli.uploadPart(upload, num, out, progress.part(num))
 		if err != nil {
+			fail()
+			wg.Wait()
 			return err
 		}
 		parts[num-1] = part
 	}
 	wg.Wait()
+	if err = cli.Context().Err(); err != nil {
+		fail()
+		return
+	}
 	if ok && upload != nil {
 		if _, err = cli.CompleteMultipartUpload(upload, parts[0:num]); err != nil {
 			logger.Printf(err.Error())
-			ok = false
+			fail()
 		} else {
 			logger.Printf("Succeeded to upload an object %s:%s", bucket, key)
 		}
@@ -998,20 +1658,29 @@ func (cli *DefaultStorageClient) ResumeUploadFile(bucket, key, uploadId string,
 	if fd == nil {
 		return errors.New("no such file")
 	}
+	defer cli.countTransferred(&err)
 	logger := cli.env.Logger
 	fi, _ := fd.Stat()
 	size := fi.Size()
+	progress := cli.newTransferProgress(bucket, key, size)
+	defer func() { progress.done(err) }()
 	chunkSize := cli.Config.MultipartChunkSize
 	if size <= cli.Config.MultipartChunkSize {
-		return cli.PutObject(bucket, key, fd, metadata)
+		return cli.putObjectAt(bucket, key, fd, 0, size, metadata, progress.part(0))
 	}
 	logger.Printf("Uploading %s -> %s:%s ...", fd.Name(), bucket, key)
 	var (
 		num   = int(math.Ceil(float64(size) / float64(chunkSize)))
 		parts = make([]*Part, int(num))
 		wg    sync.WaitGroup
+		mu    sync.Mutex
 		ok    = true
 	)
+	fail := func() {
+		mu.Lock()
+		ok = false
+		mu.Unlock()
+	}
 	upload := &MultipartUpload{
 		Bucket:   bucket,
 		Key:      key,
@@ -1020,36 +1689,40 @@ func (cli *DefaultStorageClient) ResumeUploadFile(bucket, key, uploadId string,
 	listing, err := cli.ListParts(bucket, key, uploadId, 0, 1000)
 	defer func() {
 		if !ok {
-			if upload != nil && cli.Config.AbortOnFailure {
-				cli.AbortMultipartUpload(upload)
-			}
-			if err == nil {
-				err = errors.New("failed to upload file(s)")
-			}
+			err = cli.failMultipartUpload(upload, err)
 		}
 	}()
 	if err != nil {
 		return
 	}
-	ch := make(chan bool)
+	concurrency := cli.env.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
 	for i := 1; i <= num; i++ {
 		wg.Add(1)
+		sem <- struct{}{}
 		go func(filename string, num int) {
-			<-ch
 			defer func() {
+				<-sem
 				wg.Done()
-				ch <- true
 			}()
+			if cli.Context().Err() != nil {
+				fail()
+				return
+			}
 			partD := listing.GetPart(num)
 			if partD != nil {
 				parts[num-1] = &partD.Part
+				progress.skip(num, partD.Size)
 				return
 			}
 			logger.Printf("Uploading a part (UploadNumber: %d) ...", num)
 			f, err := os.Open(filename)
 			if err != nil {
 				logger.Println(err.Error())
-				ok = false
+				fail()
 				return
 			}
 			defer f.Close()
@@ -1059,26 +1732,23 @@ func (cli *DefaultStorageClient) ResumeUploadFile(bucket, key, uploadId string,
 				n = size - off
 			}
 			logger.Printf("File: %s Offset: %d, Size: %d", filename, off, n)
-			part, err := cli.UploadPartAt(upload, num, f, off, n)
+			part, err := cli.uploadPartAt(upload, num, f, off, n, progress.part(num))
 			if part == nil || err != nil {
-				ok = false
+				fail()
 				return
 			}
 			parts[num-1] = part
 			logger.Printf("Finished to upload Part(%s).", part)
 		}(fd.Name(), i)
 	}
-	concurrency := cli.env.Concurrency
-	if concurrency > num {
-		concurrency = num
-	}
-	for i := 0; i < concurrency; i++ {
-		ch <- true
-	}
 	wg.Wait()
+	if err = cli.Context().Err(); err != nil {
+		fail()
+		return
+	}
 	if ok {
 		if _, err = cli.CompleteMultipartUpload(upload, parts); err != nil {
-			ok = false
+			fail()
 		} else {
 			logger.Printf("Succeeded to upload %s as %s:%s", fd.Name(), bucket, key)
 		}
@@ -1091,12 +1761,15 @@ func (cli *DefaultStorageClient) UploadFile(bucket, key string, fd *os.File, met
 	if fd == nil {
 		return errors.New("no such file")
 	}
+	defer cli.countTransferred(&err)
 	logger := cli.env.Logger
 	fi, _ := fd.Stat()
 	size := fi.Size()
+	progress := cli.newTransferProgress(bucket, key, size)
+	defer func() { progress.done(err) }()
 	multipartChunkSize := cli.Config.MultipartChunkSize
 	if size <= cli.Config.MultipartChunkSize {
-		return cli.PutObject(bucket, key, fd, metadata)
+		return cli.putObjectAt(bucket, key, fd, 0, size, metadata, progress.part(0))
 	}
 	logger.Printf("Uploading %s -> %s:%s ...", fd.Name(), bucket, key)
 	var (
@@ -1104,36 +1777,45 @@ func (cli *DefaultStorageClient) UploadFile(bucket, key string, fd *os.File, met
 		parts  = make([]*Part, int(num))
 		wg     sync.WaitGroup
 		upload *MultipartUpload
+		mu     sync.Mutex
 		ok     = true
 	)
+	fail := func() {
+		mu.Lock()
+		ok = false
+		mu.Unlock()
+	}
 	defer func() {
 		if !ok {
-			if upload != nil && cli.Config.AbortOnFailure {
-				cli.AbortMultipartUpload(upload)
-			}
-			if err == nil {
-				err = errors.New("failed to upload file(s)")
-			}
+			err = cli.failMultipartUpload(upload, err)
 		}
 	}()
 	upload, err = cli.InitiateMultipartUpload(bucket, key, metadata)
 	if err != nil {
 		return
 	}
-	ch := make(chan bool)
+	concurrency := cli.env.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
 	for i := 1; i <= num; i++ {
 		wg.Add(1)
+		sem <- struct{}{}
 		go func(filename string, num int) {
-			<-ch
 			defer func() {
+				<-sem
 				wg.Done()
-				ch <- true
 			}()
+			if cli.Context().Err() != nil {
+				fail()
+				return
+			}
 			logger.Printf("Uploading a part (UploadNumber: %d) ...", num)
 			f, err := os.Open(filename)
 			if err != nil {
 				logger.Println(err.Error())
-				ok = false
+				fail()
 				return
 			}
 			defer f.Close()
@@ -1143,26 +1825,23 @@ func (cli *DefaultStorageClient) UploadFile(bucket, key string, fd *os.File, met
 				n = size - off
 			}
 			logger.Printf("File: %s Offset: %d, Size: %d", filename, off, n)
-			part, err := cli.UploadPartAt(upload, num, f, off, n)
+			part, err := cli.uploadPartAt(upload, num, f, off, n, progress.part(num))
 			if part == nil || err != nil {
-				ok = false
+				fail()
 				return
 			}
 			parts[num-1] = part
 			logger.Printf("Finished to upload Part(%s).", part)
 		}(fd.Name(), i)
 	}
-	concurrency := cli.env.Concurrency
-	if concurrency > num {
-		concurrency = num
-	}
-	for i := 0; i < concurrency; i++ {
-		ch <- true
-	}
 	wg.Wait()
+	if err = cli.Context().Err(); err != nil {
+		fail()
+		return
+	}
 	if ok {
 		if _, err = cli.CompleteMultipartUpload(upload, parts); err != nil {
-			ok = false
+			fail()
 		} else {
 			logger.Printf("Succeeded to upload %s as %s:%s", fd.Name(), bucket, key)
 		}
@@ -1170,10 +1849,273 @@ func (cli *DefaultStorageClient) UploadFile(bucket, key string, fd *os.File, met
 	return
 }
 
+// DownloadFile downloads a storage object to a file.
+// A large object is split into ranges of multipartChunkSize and downloaded in parallel.
+// The progress is saved to the download state file until the download completes.
+func (cli *DefaultStorageClient) DownloadFile(bucket, key string, fd *os.File) (err error) {
+	if fd == nil {
+		return errors.New("no such file")
+	}
+	summary, err := cli.getObjectSummary(bucket, key)
+	if err != nil {
+		return
+	}
+	return cli.download(fd, newDownloadState(bucket, key, summary, cli.Config.MultipartChunkSize), false)
+}
+
+// ResumeDownloadFile resumes a download of a storage object to a partially downloaded file.
+// If the object has been modified since the previous download, it is downloaded again from the beginning.
+func (cli *DefaultStorageClient) ResumeDownloadFile(bucket, key string, fd *os.File) (err error) {
+	if fd == nil {
+		return errors.New("no such file")
+	}
+	logger := cli.env.Logger
+	summary, err := cli.getObjectSummary(bucket, key)
+	if err != nil {
+		return
+	}
+	state, err := loadDownloadState(fd.Name())
+	if err != nil {
+		return
+	}
+	if state == nil {
+		fi, err := fd.Stat()
+		if err != nil {
+			return err
+		}
+		if fi.Mode().IsRegular() && fi.Size() == summary.Size && fi.ModTime().Unix() == summary.LastModified.Unix() {
+			logger.Printf("%s has already been downloaded from %s:%s", fd.Name(), bucket, key)
+			cli.Stats.AddSkipped()
+			return nil
+		}
+		return cli.download(fd, newDownloadState(bucket, key, summary, cli.Config.MultipartChunkSize), false)
+	}
+	if !state.matches(bucket, key, summary) {
+		logger.Printf("%s:%s has been modified since the previous download. Restart to download it.", bucket, key)
+		return cli.download(fd, newDownloadState(bucket, key, summary, cli.Config.MultipartChunkSize), false)
+	}
+	logger.Printf("Resuming to download %s:%s -> %s ...", bucket, key, fd.Name())
+	return cli.download(fd, state, true)
+}
+
+// getObjectSummary is like GetObjectSummary but returns an error if the object does not exist.
+func (cli *DefaultStorageClient) getObjectSummary(bucket, key string) (summary *ObjectSummary, err error) {
+	if summary, err = cli.GetObjectSummary(bucket, key); err != nil {
+		return
+	}
+	if summary == nil {
+		return nil, fmt.Errorf("no such object: %s:%s", bucket, key)
+	}
+	return
+}
+
+// download downloads an object according to the download state.
+// If resume is true, the parts already downloaded (or the bytes already written for a single part object) are skipped.
+// If the file is not a regular file (e.g., /dev/null or a pipe), the object is streamed with a single request
+// without the download state file.
+func (cli *DefaultStorageClient) download(fd *os.File, state *downloadState, resume bool) (err error) {
+	logger := cli.env.Logger
+	bucket, key, size := state.Bucket, state.Key, state.Size
+	fi, err := fd.Stat()
+	if err != nil {
+		return
+	}
+	progress := cli.newTransferProgress(bucket, key, size)
+	defer func() { progress.done(err) }()
+	if !fi.Mode().IsRegular() {
+		if _, err = cli.streamObject(bucket, key, fd, progress); err != nil {
+			return
+		}
+		logger.Printf("Succeeded to download %s:%s to %s", bucket, key, fd.Name())
+		cli.Stats.AddTransferred()
+		return
+	}
+	stateFile := DownloadStateFilename(fd.Name())
+	if err = state.save(stateFile); err != nil {
+		return
+	}
+	if size <= state.ChunkSize {
+		err = cli.downloadObject(bucket, key, fd, size, resume, progress)
+	} else {
+		err = cli.downloadParts(fd, state, stateFile, progress)
+	}
+	if err != nil {
+		return
+	}
+	if err = os.Remove(stateFile); err != nil {
+		return
+	}
+	if !state.LastModified.IsZero() {
+		os.Chtimes(fd.Name(), time.Now(), state.LastModified)
+	}
+	logger.Printf("Succeeded to download %s:%s as %s", bucket, key, fd.Name())
+	cli.Stats.AddTransferred()
+	return
+}
+
+// countTransferred counts an object transferred if *err is nil. It is deferred by the upload methods.
+func (cli *DefaultStorageClient) countTransferred(err *error) {
+	if *err == nil {
+		cli.Stats.AddTransferred()
+	}
+}
+
+// downloadObject downloads a whole object with a single request and writes it to the file.
+// If resume is true, it downloads only the bytes following the end of the file.
+func (cli *DefaultStorageClient) downloadObject(bucket, key string, fd *os.File, size int64, resume bool, progress *transferProgress) (err error) {
+	var off int64
+	if resume {
+		fi, err := fd.Stat()
+		if err != nil {
+			return err
+		}
+		if off = fi.Size(); off > size {
+			off = 0
+		}
+	}
+	if 0 < off {
+		progress.skip(0, off)
+		if off < size {
+			if err = cli.downloadRangeAt(bucket, key, fd, off, size-off, size, progress.part(0)); err != nil {
+				return
+			}
+		}
+		return fd.Truncate(size)
+	}
+	if _, err = fd.Seek(0, io.SeekStart); err != nil {
+		return
+	}
+	n, err := cli.streamObject(bucket, key, fd, progress)
+	if err != nil {
+		return
+	}
+	return fd.Truncate(n)
+}
+
+// streamObject downloads a whole object with a single request and writes it to w.
+func (cli *DefaultStorageClient) streamObject(bucket, key string, w io.Writer, progress *transferProgress) (n int64, err error) {
+	r, err := cli.GetObject(bucket, key)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+	bw := bufio.NewWriter(w)
+	part := progress.part(0)
+	if n, err = bufio.NewReader(part.reader(r)).WriteTo(bw); err != nil {
+		return
+	}
+	if err = bw.Flush(); err != nil {
+		return
+	}
+	part.completed()
+	return
+}
+
+// downloadParts downloads the parts of an object in parallel, and records completed parts to the state file.
+func (cli *DefaultStorageClient) downloadParts(fd *os.File, state *downloadState, stateFile string, progress *transferProgress) (err error) {
+	logger := cli.env.Logger
+	bucket, key, size, chunkSize := state.Bucket, state.Key, state.Size, state.ChunkSize
+	logger.Printf("Downloading %s:%s -> %s ...", bucket, key, fd.Name())
+	if err = fd.Truncate(size); err != nil {
+		return
+	}
+	var (
+		num  = int(math.Ceil(float64(size) / float64(chunkSize)))
+		done = state.completed()
+		nums []int
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		ok   = true
+	)
+	for i := 1; i <= num; i++ {
+		if done[i] {
+			logger.Printf("Skip a downloaded part (PartNumber: %d).", i)
+			n := chunkSize
+			if off := chunkSize * int64(i-1); off+n > size {
+				n = size - off
+			}
+			progress.skip(i, n)
+			continue
+		}
+		nums = append(nums, i)
+	}
+	concurrency := cli.env.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+	fail := func() {
+		mu.Lock()
+		ok = false
+		mu.Unlock()
+	}
+	for _, i := range nums {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(num int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if cli.Context().Err() != nil {
+				fail()
+				return
+			}
+			off := chunkSize * int64(num-1)
+			n := chunkSize
+			if off+n > size {
+				n = size - off
+			}
+			logger.Printf("Downloading a part (PartNumber: %d, Offset: %d, Size: %d) ...", num, off, n)
+			if err := cli.downloadRangeAt(bucket, key, fd, off, n, size, progress.part(num)); err != nil {
+				logger.Printf("Failed to download a part (PartNumber: %d). %v", num, err)
+				fail()
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			state.Parts = append(state.Parts, num)
+			if err := state.save(stateFile); err != nil {
+				logger.Printf("Failed to save the download state: %s. %v", stateFile, err)
+			}
+			logger.Printf("Finished to download a part (PartNumber: %d).", num)
+		}(i)
+	}
+	wg.Wait()
+	if err = cli.Context().Err(); err != nil {
+		return
+	}
+	if !ok {
+		return errors.New("failed to download file(s)")
+	}
+	return
+}
+
+// downloadRangeAt downloads n bytes of an object from off and writes them to the file at the same offset.
+func (cli *DefaultStorageClient) downloadRangeAt(bucket, key string, fd *os.File, off, n, size int64, progress *partProgress) (err error) {
+	r, cr, err := cli.GetObjectRange(bucket, key, off, n)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+	if cr.First != off || cr.Length() != n || cr.Total != size {
+		return fmt.Errorf("unexpected content range: %s (the object may have been modified)", cr)
+	}
+	written, err := io.Copy(&offsetWriter{w: fd, off: off}, progress.reader(r))
+	if err != nil {
+		return
+	}
+	if written != n {
+		return fmt.Errorf("short read: %d of %d bytes", written, n)
+	}
+	progress.completed()
+	return
+}
+
 // GetStorageSpace returns a usage of the DAG storage.
 func (cli *DefaultStorageClient) GetStorageSpace() (usage *StorageSpace, err error) {
 	if cli.env.Debug {
-		cli.env.Logger.Println("Storage REST API Call: GET Service space")
+		cli.logf(levelDebug, "Storage REST API Call: GET Service space")
 	}
 	urlStr := cli.Config.buildURL("", "", map[string]string{"space": ""})
 	_, err = cli.DoAndRetry(func() (*http.Request, error) {
@@ -1181,7 +2123,7 @@ func (cli *DefaultStorageClient) GetStorageSpace() (usage *StorageSpace, err err
 		return req, nil
 	}, &usage)
 	if err != nil {
-		cli.Logger.Println("Failed to execute HTTP request.", err)
+		cli.logf(levelError, "Failed to execute HTTP request. %v", err)
 		return
 	}
 	return
@@ -1190,19 +2132,19 @@ func (cli *DefaultStorageClient) GetStorageSpace() (usage *StorageSpace, err err
 // ListNetworkTraffics returns list of network traffic every 1 day.
 func (cli *DefaultStorageClient) ListNetworkTraffics(backwardTo int) (result *ListTrafficResult, err error) {
 	if cli.env.Debug {
-		cli.env.Logger.Printf("Storage REST API Call: GET Service traffic (backwardTo: %v)", backwardTo)
+		cli.logf(levelDebug, "Storage REST API Call: GET Service traffic (backwardTo: %v)", backwardTo)
 	}
 	urlStr := cli.Config.buildURL("", "", map[string]string{"traffic": "", "backwardTo": strconv.Itoa(backwardTo)})
 	_, err = cli.DoAndRetry(func() (*http.Request, error) {
 		req, err := http.NewRequest("GET", urlStr, nil)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListParts. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListParts. reason: %v", err)
 			return nil, err
 		}
 		return req, nil
 	}, &result)
 	if err != nil {
-		cli.Logger.Println("Failed to execute HTTP request.", err)
+		cli.logf(levelError, "Failed to execute HTTP request. %v", err)
 		return
 	}
 	return
@@ -1212,19 +2154,19 @@ func (cli *DefaultStorageClient) ListNetworkTraffics(backwardTo int) (result *Li
 func (cli *DefaultStorageClient) GetNetworkTraffic(date string) (traffic *DownTraffic, err error) {
 	var traffics *ListTrafficResult
 	if cli.env.Debug {
-		cli.env.Logger.Printf("Storage REST API Call: GET Service traffic (chargeDate: %s)", date)
+		cli.logf(levelDebug, "Storage REST API Call: GET Service traffic (chargeDate: %s)", date)
 	}
 	urlStr := cli.Config.buildURL("", "", map[string]string{"traffic": "", "chargeDate": date})
 	_, err = cli.DoAndRetry(func() (*http.Request, error) {
 		req, err := http.NewRequest("GET", urlStr, nil)
 		if err != nil {
-			cli.Logger.Printf("Failed to create a new HTTP request for ListParts. reason: %v\n", err)
+			cli.logf(levelError, "Failed to create a new HTTP request for ListParts. reason: %v", err)
 			return nil, err
 		}
 		return req, nil
 	}, &traffics)
 	if err != nil {
-		cli.Logger.Println("Failed to execute HTTP request.", err)
+		cli.logf(levelError, "Failed to execute HTTP request. %v", err)
 		return
 	}
 	if traffics != nil && len(traffics.DownTraffics) > 0 {
@@ -1235,120 +2177,203 @@ func (cli *DefaultStorageClient) GetNetworkTraffic(date string) (traffic *DownTr
 
 // Sign calculates a signature string and set to the Authorization header.
 func (cli *DefaultStorageClient) Sign(req *http.Request) error {
-	var (
-		accessKeyID     = cli.Config.AccessKeyID
-		secretAccessKey = cli.Config.SecretAccessKey
-	)
+	accessKeyID, secretAccessKey, err := cli.accessKeys()
+	if err != nil {
+		return err
+	}
 	if accessKeyID == "" || secretAccessKey == "" {
 		return errors.New("please check your access_key_id and secret_access_key, and try again")
 	}
-	now := time.Now()
-	date := req.Header.Get("Date")
-	if date == "" {
-		date = now.In(time.UTC).Format(time.RFC1123)
-		req.Header.Set("Date", date)
-	}
-	contentType := req.Header.Get("Content-Type")
-	if contentType == "" {
-		contentType = "application/octet-stream"
-		req.Header.Set("Content-Type", contentType)
-	}
-	canonHeaders := getCanonicalHeaders(req.Header)
-	canonResource := getCanonicalResource(req.URL)
-	headers := req.Header
-	stringToSign := fmt.Sprintf("%s\n%s\n%s\n%s\n%s%s",
-		req.Method,
-		headers.Get("Content-MD5"),
-		contentType,
-		date,
-		canonHeaders,
-		canonResource)
-	if cli.env.Debug {
-		cli.Logger.Printf("StringToSign = %q", stringToSign)
+	signer, err := cli.signer()
+	if err != nil {
+		return err
+	}
+	return signer.Sign(req)
+}
+
+// PresignURL returns an URL of the object signed by query string authentication, which is valid until expires.
+// The headers (e.g., Content-Type) are signed together, so a request with the URL must have the same values.
+func (cli *DefaultStorageClient) PresignURL(method, bucket, key string, expires time.Time, header http.Header) (string, error) {
+	accessKeyID, secretAccessKey, err := cli.accessKeys()
+	if err != nil {
+		return "", err
+	}
+	if accessKeyID == "" || secretAccessKey == "" {
+		return "", errors.New("please check your access_key_id and secret_access_key, and try again")
+	}
+	signer, err := cli.signer()
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest(method, cli.Config.buildURL(bucket, key, nil), nil)
+	if err != nil {
+		return "", err
+	}
+	if header != nil {
+		req.Header = header
+	}
+	return signer.Presign(req, expires)
+}
+
+// signer returns the Signer of the client. If cli.Signer is nil, it is built from Config.
+func (cli *DefaultStorageClient) signer() (Signer, error) {
+	if cli.Signer != nil {
+		return cli.Signer, nil
+	}
+	accessKeyID, secretAccessKey, err := cli.accessKeys()
+	if err != nil {
+		return nil, err
+	}
+	c := cli.Config
+	signer, err := NewSigner(c.SignatureVersion, accessKeyID, secretAccessKey, c.Vendor, c.Region)
+	if err != nil {
+		return nil, err
 	}
-	// calculate the signature
-	mac := hmac.New(sha1.New, []byte(secretAccessKey))
-	mac.Write([]byte(stringToSign))
-	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
 	if cli.env.Debug {
-		cli.Logger.Printf("Signature = %q", signature)
+		logger := log.New(env.NewLogWriter(cli.eventLogger(), levelDebug), "", 0)
+		switch s := signer.(type) {
+		case *V2Signer:
+			s.Logger = logger
+		case *V4Signer:
+			s.Logger = logger
+		}
 	}
-	// "Authorization" header string
-	authorization := fmt.Sprintf("%s %s:%s", cli.Config.Vendor, accessKeyID, signature)
-	req.Header.Set("Authorization", authorization)
-	return nil
+	return signer, nil
+}
+
+// accessKeys returns the keys to sign requests. The Credentials of the client are retrieved only once,
+// so that an external command (credentialProcess) runs only if the command sends a request.
+func (cli *DefaultStorageClient) accessKeys() (accessKeyID, secretAccessKey string, err error) {
+	if cli.initErr != nil {
+		return "", "", cli.initErr
+	}
+	if cli.Credentials == nil {
+		return cli.Config.AccessKeyID, cli.Config.SecretAccessKey, nil
+	}
+	var creds *Credentials
+	if cli.creds == nil {
+		creds, err = cli.retrieveCredentials()
+	} else {
+		cli.creds.once.Do(func() {
+			cli.creds.creds, cli.creds.err = cli.retrieveCredentials()
+		})
+		creds, err = cli.creds.creds, cli.creds.err
+	}
+	if err != nil || creds == nil {
+		return "", "", err
+	}
+	return creds.AccessKeyID, creds.SecretAccessKey, nil
+}
+
+// retrieveCredentials returns the credentials of the Credentials, or nil if it has none.
+func (cli *DefaultStorageClient) retrieveCredentials() (*Credentials, error) {
+	creds, err := cli.Credentials.Retrieve()
+	switch err {
+	case nil:
+		cli.logf(levelDebug, "Credentials are loaded from %s", creds.Source)
+		return creds, nil
+	case ErrNoCredentials:
+		return nil, nil
+	}
+	err = fmt.Errorf("failed to load credentials: %v", err)
+	cli.logf(levelError, "%v", err)
+	return nil, err
 }
 
-// DoAndRetry executes Do method and if the response is error (< 400), retries http requests.
+// DoAndRetry executes Do method and retries the request according to the RetryPolicy if it fails.
 func (cli *DefaultStorageClient) DoAndRetry(fn func() (*http.Request, error), result interface{}) (resp *http.Response, err error) {
-	req, err := fn()
-	if err != nil {
-		return
+	policy := cli.RetryPolicy
+	if policy == nil {
+		policy = NewDefaultRetryPolicy(cli.Config.Retry, cli.Config.RetryInterval, cli.Config.MaxBackoff, cli.Config.RetryOn)
+	}
+	if _, _, err = cli.accessKeys(); err != nil {
+		return nil, err
 	}
-	resp, err = cli.Do(req, result)
-	for retryCount := 1; retryCount <= cli.Config.Retry; retryCount++ {
-		if resp != nil && resp.StatusCode < 400 {
+	ctx := cli.Context()
+	for attempt := 1; ; attempt++ {
+		var req *http.Request
+		if req, err = fn(); err != nil {
+			return nil, err
+		}
+		resp, err = cli.Do(req, result)
+		if err == nil {
 			return resp, nil
 		}
-		if resp != nil && resp.StatusCode == 404 {
-			// NotFound
-			break
+		if ctx.Err() != nil {
+			closeResponse(resp)
+			return nil, ctx.Err()
 		}
-		time.Sleep(cli.Config.RetryInterval)
-		req, err := fn()
-		if err != nil {
-			continue
+		if !policy.ShouldRetry(attempt, resp, err) {
+			return resp, err
 		}
-		resp, err = cli.Do(req, result)
-		if err != nil {
-			cli.Logger.Printf("Failed to request. %v (retry: %d/%d)", err, retryCount, cli.Config.Retry)
+		wait := policy.Backoff(attempt, resp)
+		cli.Stats.AddRetry()
+		cli.eventLogger().Log(levelWarn, "retrying request", "operation", operationName(req), "error", err,
+			"attempt", attempt, "waitMs", int64(wait/time.Millisecond))
+		closeResponse(resp)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
 		}
 	}
-	return
 }
 
 // Do sends an HTTP request and returns an HTTP response
 func (cli *DefaultStorageClient) Do(req *http.Request, result interface{}) (resp *http.Response, err error) {
-	httpcli := cli.HTTPClient(cli)
-	if cli.Config.AccessKeyID != "" {
+	httpcli := cli.httpClient()
+	accessKeyID, _, err := cli.accessKeys()
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(cli.Context())
+	if accessKeyID != "" {
 		cli.Sign(req)
 	}
 	req.Header.Set("User-Agent", fmt.Sprintf("dagtools/%s", cli.env.Version))
+	var (
+		logger      = cli.eventLogger()
+		operation   = operationName(req)
+		bucket, key = bucketAndKey(req.URL)
+		start       = time.Now()
+	)
 	if cli.env.Debug {
-		cli.Logger.Println("Sending HTTP request ...")
+		cli.logHeader(fmt.Sprintf(">> %s %s %s", req.Method, req.URL, req.Proto), req.Header)
 	}
-	cli.Logger.Printf(">> %s %s %s", req.Method, req.URL, req.Proto)
-	if cli.env.Debug {
-		for key, values := range req.Header {
-			_key := key
-			if strings.HasPrefix(_key, "X-") {
-				_key = strings.ToLower(_key)
-			}
-			cli.Logger.Printf(">> %s: %s", _key, strings.Join(values, ","))
-		}
+	if cli.Stats != nil && req.Body != nil && req.Body != http.NoBody {
+		req.Body = &countingReadCloser{rc: req.Body, count: cli.Stats.AddSent}
+	}
+	if cli.uploadLimiter != nil && req.Body != nil && req.Body != http.NoBody {
+		req.Body = &rateLimitedReadCloser{rc: req.Body, l: cli.uploadLimiter, ctx: cli.Context()}
 	}
 	resp, err = httpcli.Do(req)
 	if err != nil || resp == nil {
 		if err == nil {
 			err = errors.New("unknown error")
 		}
+		cli.Stats.AddRequest(req.Method, 0)
+		logger.Log(levelWarn, "request failed", "operation", operation, "method", req.Method, "bucket", bucket, "key", key,
+			"error", err, "durationMs", msSince(start))
 		return
 	}
 	if cli.env.Debug {
-		cli.Logger.Println("Received HTTP response.")
+		cli.logHeader(fmt.Sprintf("<< %s %s", resp.Proto, resp.Status), resp.Header)
 	}
-	cli.Logger.Printf("<< %s %s", resp.Proto, resp.Status)
-	if cli.env.Debug {
-		for key, values := range resp.Header {
-			_key := key
-			if strings.HasPrefix(_key, "X-") {
-				_key = strings.ToLower(_key)
-			}
-			cli.Logger.Printf("<< %s: %s", _key, strings.Join(values, ","))
-		}
+	cli.Stats.AddRequest(req.Method, resp.StatusCode)
+	if cli.Stats != nil && resp.Body != nil {
+		resp.Body = &countingReadCloser{rc: resp.Body, count: cli.Stats.AddReceived}
+	}
+	if cli.downloadLimiter != nil && resp.Body != nil {
+		resp.Body = &rateLimitedReadCloser{rc: resp.Body, l: cli.downloadLimiter, ctx: cli.Context()}
+	}
+	level := levelInfo
+	if resp.StatusCode >= 400 {
+		level = levelWarn
 	}
+	logger.Log(level, "request", "operation", operation, "method", req.Method, "bucket", bucket, "key", key,
+		"status", resp.StatusCode, "requestId", requestID(resp.Header), "durationMs", msSince(start))
 	if resp.StatusCode >= 300 {
-		requestId := resp.Header.Get("x-iijgio-request-id")
+		requestId := requestID(resp.Header)
 		var e ErrorResponse
 		if resp.Header.Get("Content-Type") == "application/xml" {
 			unmarshal(resp.Body, &e)
@@ -1360,7 +2385,7 @@ func (cli *DefaultStorageClient) Do(req *http.Request, result interface{}) (resp
 		return resp, e
 	}
 	if resp.ContentLength < 0 && cli.env.Debug {
-		cli.Logger.Printf("Illegal Content-Length detect. value = %q", resp.ContentLength)
+		cli.logf(levelDebug, "Illegal Content-Length detect. value = %q", resp.ContentLength)
 	}
 	if result != nil {
 		err = unmarshal(resp.Body, result)
@@ -1411,6 +2436,14 @@ func getCanonicalHeaders(h http.Header) string {
 	return strings.Join(canonicalHeaders, "")
 }
 
+// closeResponse discards the rest of the response body and closes it.
+func closeResponse(resp *http.Response) {
+	if resp != nil && resp.Body != nil {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}
+}
+
 // Unmarshal XML file into Go struct.
 func unmarshal(r io.ReadCloser, o interface{}) (err error) {
 	if r != nil && o != nil {
@@ -1418,6 +2451,8 @@ func unmarshal(r io.ReadCloser, o interface{}) (err error) {
 		br := bufio.NewReader(r)
 		dec := xml.NewDecoder(br)
 		err = dec.Decode(o)
+		// read the rest of the body to reuse the connection
+		io.Copy(ioutil.Discard, br)
 	}
 	return
 }
@@ -5,11 +5,13 @@
 package client
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	io "io"
 	http "net/http"
 	os "os"
 	reflect "reflect"
+	time "time"
 )
 
 // MockStorageClient is a mock of StorageClient interface
@@ -161,6 +163,117 @@ func (mr *MockStorageClientMockRecorder) DeleteBucketPolicy(bucket interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBucketPolicy", reflect.TypeOf((*MockStorageClient)(nil).DeleteBucketPolicy), bucket)
 }
 
+// GetBucketAcl mocks base method
+func (m *MockStorageClient) GetBucketAcl(bucket string) (*AccessControlPolicy, error) {
+	ret := m.ctrl.Call(m, "GetBucketAcl", bucket)
+	ret0, _ := ret[0].(*AccessControlPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBucketAcl indicates an expected call of GetBucketAcl
+func (mr *MockStorageClientMockRecorder) GetBucketAcl(bucket interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBucketAcl", reflect.TypeOf((*MockStorageClient)(nil).GetBucketAcl), bucket)
+}
+
+// PutBucketAcl mocks base method
+func (m *MockStorageClient) PutBucketAcl(bucket string, acl *AccessControlPolicy) error {
+	ret := m.ctrl.Call(m, "PutBucketAcl", bucket, acl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutBucketAcl indicates an expected call of PutBucketAcl
+func (mr *MockStorageClientMockRecorder) PutBucketAcl(bucket, acl interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutBucketAcl", reflect.TypeOf((*MockStorageClient)(nil).PutBucketAcl), bucket, acl)
+}
+
+// PutBucketCannedAcl mocks base method
+func (m *MockStorageClient) PutBucketCannedAcl(bucket, acl string) error {
+	ret := m.ctrl.Call(m, "PutBucketCannedAcl", bucket, acl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutBucketCannedAcl indicates an expected call of PutBucketCannedAcl
+func (mr *MockStorageClientMockRecorder) PutBucketCannedAcl(bucket, acl interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutBucketCannedAcl", reflect.TypeOf((*MockStorageClient)(nil).PutBucketCannedAcl), bucket, acl)
+}
+
+// GetBucketCors mocks base method
+func (m *MockStorageClient) GetBucketCors(bucket string) (*CORSConfiguration, error) {
+	ret := m.ctrl.Call(m, "GetBucketCors", bucket)
+	ret0, _ := ret[0].(*CORSConfiguration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBucketCors indicates an expected call of GetBucketCors
+func (mr *MockStorageClientMockRecorder) GetBucketCors(bucket interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBucketCors", reflect.TypeOf((*MockStorageClient)(nil).GetBucketCors), bucket)
+}
+
+// PutBucketCors mocks base method
+func (m *MockStorageClient) PutBucketCors(bucket string, cors *CORSConfiguration) error {
+	ret := m.ctrl.Call(m, "PutBucketCors", bucket, cors)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutBucketCors indicates an expected call of PutBucketCors
+func (mr *MockStorageClientMockRecorder) PutBucketCors(bucket, cors interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutBucketCors", reflect.TypeOf((*MockStorageClient)(nil).PutBucketCors), bucket, cors)
+}
+
+// DeleteBucketCors mocks base method
+func (m *MockStorageClient) DeleteBucketCors(bucket string) error {
+	ret := m.ctrl.Call(m, "DeleteBucketCors", bucket)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBucketCors indicates an expected call of DeleteBucketCors
+func (mr *MockStorageClientMockRecorder) DeleteBucketCors(bucket interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBucketCors", reflect.TypeOf((*MockStorageClient)(nil).DeleteBucketCors), bucket)
+}
+
+// GetBucketWebsite mocks base method
+func (m *MockStorageClient) GetBucketWebsite(bucket string) (*WebsiteConfiguration, error) {
+	ret := m.ctrl.Call(m, "GetBucketWebsite", bucket)
+	ret0, _ := ret[0].(*WebsiteConfiguration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBucketWebsite indicates an expected call of GetBucketWebsite
+func (mr *MockStorageClientMockRecorder) GetBucketWebsite(bucket interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBucketWebsite", reflect.TypeOf((*MockStorageClient)(nil).GetBucketWebsite), bucket)
+}
+
+// PutBucketWebsite mocks base method
+func (m *MockStorageClient) PutBucketWebsite(bucket string, website *WebsiteConfiguration) error {
+	ret := m.ctrl.Call(m, "PutBucketWebsite", bucket, website)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutBucketWebsite indicates an expected call of PutBucketWebsite
+func (mr *MockStorageClientMockRecorder) PutBucketWebsite(bucket, website interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutBucketWebsite", reflect.TypeOf((*MockStorageClient)(nil).PutBucketWebsite), bucket, website)
+}
+
+// DeleteBucketWebsite mocks base method
+func (m *MockStorageClient) DeleteBucketWebsite(bucket string) error {
+	ret := m.ctrl.Call(m, "DeleteBucketWebsite", bucket)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBucketWebsite indicates an expected call of DeleteBucketWebsite
+func (mr *MockStorageClientMockRecorder) DeleteBucketWebsite(bucket interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBucketWebsite", reflect.TypeOf((*MockStorageClient)(nil).DeleteBucketWebsite), bucket)
+}
+
 // ListObjects mocks base method
 func (m *MockStorageClient) ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (*ObjectListing, error) {
 	ret := m.ctrl.Call(m, "ListObjects", bucket, prefix, marker, delimiter, maxKeys)
@@ -211,6 +324,19 @@ func (mr *MockStorageClientMockRecorder) PutObjectAt(bucket, key, data, off, len
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutObjectAt", reflect.TypeOf((*MockStorageClient)(nil).PutObjectAt), bucket, key, data, off, length, metadata)
 }
 
+// CopyObject mocks base method
+func (m *MockStorageClient) CopyObject(srcBucket, srcKey, bucket, key string, metadata *ObjectMetadata) (*CopyObjectResult, error) {
+	ret := m.ctrl.Call(m, "CopyObject", srcBucket, srcKey, bucket, key, metadata)
+	ret0, _ := ret[0].(*CopyObjectResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CopyObject indicates an expected call of CopyObject
+func (mr *MockStorageClientMockRecorder) CopyObject(srcBucket, srcKey, bucket, key, metadata interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyObject", reflect.TypeOf((*MockStorageClient)(nil).CopyObject), srcBucket, srcKey, bucket, key, metadata)
+}
+
 // GetObject mocks base method
 func (m *MockStorageClient) GetObject(bucket, key string) (io.ReadCloser, error) {
 	ret := m.ctrl.Call(m, "GetObject", bucket, key)
@@ -224,6 +350,20 @@ func (mr *MockStorageClientMockRecorder) GetObject(bucket, key interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetObject", reflect.TypeOf((*MockStorageClient)(nil).GetObject), bucket, key)
 }
 
+// GetObjectRange mocks base method
+func (m *MockStorageClient) GetObjectRange(bucket, key string, off, length int64) (io.ReadCloser, *ContentRange, error) {
+	ret := m.ctrl.Call(m, "GetObjectRange", bucket, key, off, length)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(*ContentRange)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetObjectRange indicates an expected call of GetObjectRange
+func (mr *MockStorageClientMockRecorder) GetObjectRange(bucket, key, off, length interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetObjectRange", reflect.TypeOf((*MockStorageClient)(nil).GetObjectRange), bucket, key, off, length)
+}
+
 // DoesObjectExist mocks base method
 func (m *MockStorageClient) DoesObjectExist(bucket, key string) (bool, error) {
 	ret := m.ctrl.Call(m, "DoesObjectExist", bucket, key)
@@ -288,6 +428,43 @@ func (mr *MockStorageClientMockRecorder) DeleteMultipleObjects(bucket, keys, qui
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMultipleObjects", reflect.TypeOf((*MockStorageClient)(nil).DeleteMultipleObjects), bucket, keys, quiet)
 }
 
+// GetObjectAcl mocks base method
+func (m *MockStorageClient) GetObjectAcl(bucket, key string) (*AccessControlPolicy, error) {
+	ret := m.ctrl.Call(m, "GetObjectAcl", bucket, key)
+	ret0, _ := ret[0].(*AccessControlPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetObjectAcl indicates an expected call of GetObjectAcl
+func (mr *MockStorageClientMockRecorder) GetObjectAcl(bucket, key interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetObjectAcl", reflect.TypeOf((*MockStorageClient)(nil).GetObjectAcl), bucket, key)
+}
+
+// PutObjectAcl mocks base method
+func (m *MockStorageClient) PutObjectAcl(bucket, key string, acl *AccessControlPolicy) error {
+	ret := m.ctrl.Call(m, "PutObjectAcl", bucket, key, acl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutObjectAcl indicates an expected call of PutObjectAcl
+func (mr *MockStorageClientMockRecorder) PutObjectAcl(bucket, key, acl interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutObjectAcl", reflect.TypeOf((*MockStorageClient)(nil).PutObjectAcl), bucket, key, acl)
+}
+
+// PutObjectCannedAcl mocks base method
+func (m *MockStorageClient) PutObjectCannedAcl(bucket, key, acl string) error {
+	ret := m.ctrl.Call(m, "PutObjectCannedAcl", bucket, key, acl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutObjectCannedAcl indicates an expected call of PutObjectCannedAcl
+func (mr *MockStorageClientMockRecorder) PutObjectCannedAcl(bucket, key, acl interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutObjectCannedAcl", reflect.TypeOf((*MockStorageClient)(nil).PutObjectCannedAcl), bucket, key, acl)
+}
+
 // ListMultipartUploads mocks base method
 func (m *MockStorageClient) ListMultipartUploads(bucket, prefix, keyMarker, uploadIdMarker, delimiter string, maxUploads int) (*MultipartUploadListing, error) {
 	ret := m.ctrl.Call(m, "ListMultipartUploads", bucket, prefix, keyMarker, uploadIdMarker, delimiter, maxUploads)
@@ -416,6 +593,68 @@ func (mr *MockStorageClientMockRecorder) Sign(req interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sign", reflect.TypeOf((*MockStorageClient)(nil).Sign), req)
 }
 
+// PresignURL mocks base method
+func (m *MockStorageClient) PresignURL(method, bucket, key string, expires time.Time, header http.Header) (string, error) {
+	ret := m.ctrl.Call(m, "PresignURL", method, bucket, key, expires, header)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PresignURL indicates an expected call of PresignURL
+func (mr *MockStorageClientMockRecorder) PresignURL(method, bucket, key, expires, header interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PresignURL", reflect.TypeOf((*MockStorageClient)(nil).PresignURL), method, bucket, key, expires, header)
+}
+
+// PresignPostPolicy mocks base method
+func (m *MockStorageClient) PresignPostPolicy(policy *PostPolicy) (*PostForm, error) {
+	ret := m.ctrl.Call(m, "PresignPostPolicy", policy)
+	ret0, _ := ret[0].(*PostForm)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PresignPostPolicy indicates an expected call of PresignPostPolicy
+func (mr *MockStorageClientMockRecorder) PresignPostPolicy(policy interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PresignPostPolicy", reflect.TypeOf((*MockStorageClient)(nil).PresignPostPolicy), policy)
+}
+
+// WithContext mocks base method
+func (m *MockStorageClient) WithContext(ctx context.Context) StorageClient {
+	ret := m.ctrl.Call(m, "WithContext", ctx)
+	ret0, _ := ret[0].(StorageClient)
+	return ret0
+}
+
+// WithContext indicates an expected call of WithContext
+func (mr *MockStorageClientMockRecorder) WithContext(ctx interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithContext", reflect.TypeOf((*MockStorageClient)(nil).WithContext), ctx)
+}
+
+// WithRateLimit mocks base method
+func (m *MockStorageClient) WithRateLimit(uploadLimit, downloadLimit int64) StorageClient {
+	ret := m.ctrl.Call(m, "WithRateLimit", uploadLimit, downloadLimit)
+	ret0, _ := ret[0].(StorageClient)
+	return ret0
+}
+
+// WithRateLimit indicates an expected call of WithRateLimit
+func (mr *MockStorageClientMockRecorder) WithRateLimit(uploadLimit, downloadLimit interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithRateLimit", reflect.TypeOf((*MockStorageClient)(nil).WithRateLimit), uploadLimit, downloadLimit)
+}
+
+// WithProgress mocks base method
+func (m *MockStorageClient) WithProgress(listener ProgressListener) StorageClient {
+	ret := m.ctrl.Call(m, "WithProgress", listener)
+	ret0, _ := ret[0].(StorageClient)
+	return ret0
+}
+
+// WithProgress indicates an expected call of WithProgress
+func (mr *MockStorageClientMockRecorder) WithProgress(listener interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithProgress", reflect.TypeOf((*MockStorageClient)(nil).WithProgress), listener)
+}
+
 // Upload mocks base method
 func (m *MockStorageClient) Upload(bucket, key string, data io.Reader, metadata *ObjectMetadata) error {
 	ret := m.ctrl.Call(m, "Upload", bucket, key, data, metadata)
@@ -452,6 +691,30 @@ func (mr *MockStorageClientMockRecorder) ResumeUploadFile(bucket, key, uploadId,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeUploadFile", reflect.TypeOf((*MockStorageClient)(nil).ResumeUploadFile), bucket, key, uploadId, fd, metadata)
 }
 
+// DownloadFile mocks base method
+func (m *MockStorageClient) DownloadFile(bucket, key string, fd *os.File) error {
+	ret := m.ctrl.Call(m, "DownloadFile", bucket, key, fd)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DownloadFile indicates an expected call of DownloadFile
+func (mr *MockStorageClientMockRecorder) DownloadFile(bucket, key, fd interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadFile", reflect.TypeOf((*MockStorageClient)(nil).DownloadFile), bucket, key, fd)
+}
+
+// ResumeDownloadFile mocks base method
+func (m *MockStorageClient) ResumeDownloadFile(bucket, key string, fd *os.File) error {
+	ret := m.ctrl.Call(m, "ResumeDownloadFile", bucket, key, fd)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResumeDownloadFile indicates an expected call of ResumeDownloadFile
+func (mr *MockStorageClientMockRecorder) ResumeDownloadFile(bucket, key, fd interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeDownloadFile", reflect.TypeOf((*MockStorageClient)(nil).ResumeDownloadFile), bucket, key, fd)
+}
+
 // MockHTTPClient is a mock of HTTPClient interface
 type MockHTTPClient struct {
 	ctrl     *gomock.Controller
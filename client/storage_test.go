@@ -1,6 +1,13 @@
 package client
 
 import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"os"
@@ -8,7 +15,12 @@ import (
 
 	"io"
 	"io/ioutil"
+	"net"
+	"net/http/httptest"
+	"net/url"
 	"strings"
+	"sync/atomic"
+	"testing/iotest"
 	"time"
 
 	"github.com/iij/dagtools/env"
@@ -168,6 +180,45 @@ func TestSignWithCanonicalHeaders(t *testing.T) {
 	assertEquals(t, "A calculated signature was unmatched. please recheck the string-to-sign", req.Header.Get("Authorization"), "IIJGIO SAMPLE00000000000000:4B+FN8T+r5zm2K7H2VwqbfTwzp0=")
 }
 
+func TestPresignURL(t *testing.T) {
+	client := newMock()
+	expires := time.Unix(1700000000, 0)
+	u, err := client.PresignURL("GET", "mybucket", "foo/日本語.txt", expires, nil)
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+
+	mac := hmac.New(sha1.New, []byte("Sample0000000000000000000000000000000000"))
+	mac.Write([]byte("GET\n\n\n1700000000\n/mybucket/foo/%E6%97%A5%E6%9C%AC%E8%AA%9E.txt"))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	q := url.Values{}
+	q.Set("Expires", "1700000000")
+	q.Set("IIJGIOAccessKeyId", "SAMPLE00000000000000")
+	q.Set("Signature", signature)
+	assertEquals(t, "The presigned URL is invalid.", u,
+		"https://storage-dag.iijgio.com/mybucket/foo/%E6%97%A5%E6%9C%AC%E8%AA%9E.txt?"+q.Encode())
+}
+
+func TestPresignURLWithContentType(t *testing.T) {
+	client := newMock()
+	expires := time.Unix(1700000000, 0)
+	u, err := client.PresignURL("PUT", "mybucket", "foo", expires, http.Header{"Content-Type": {"text/plain"}})
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+
+	mac := hmac.New(sha1.New, []byte("Sample0000000000000000000000000000000000"))
+	mac.Write([]byte("PUT\n\ntext/plain\n1700000000\n/mybucket/foo"))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	if !strings.Contains(u, "Signature="+url.QueryEscape(signature)) {
+		t.Errorf("Should sign the Content-Type. %s", u)
+	}
+}
+
+func TestPresignURLWithoutCredentials(t *testing.T) {
+	client := newMock()
+	client.Credentials = nil
+	if _, err := client.PresignURL("GET", "mybucket", "foo", time.Now(), nil); err == nil {
+		t.Error("Should return an error without credentials.")
+	}
+}
+
 func TestGetUndecodedCanonicalResource(t *testing.T) {
 	var req *http.Request
 	url := "https://storage-dag.iijgio.com/mybucket/日本語"
@@ -226,6 +277,54 @@ func TestHTTPClientDoErrCodeWithXML(t *testing.T) {
 	assertEquals(t, "Pass through HTTP response xml check.", mockresp.Header.Get("Content-Type"), "application/xml")
 }
 
+func TestHTTPClientDoLogsEvent(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	var out bytes.Buffer
+	client.EventLogger = &env.JSONLogger{Out: &out, Level: env.LevelInfo}
+	mockresp := &http.Response{
+		Body:       NewEmptyBody(),
+		StatusCode: 200,
+		Header:     http.Header{"X-Iijgio-Request-Id": {"REQUEST0001"}},
+	}
+	req, _ := http.NewRequest("PUT", "https://storage-dag.iijgio.com/mybucket/dir/example", nil)
+	mock.EXPECT().Do(gomock.Any()).Return(mockresp, nil)
+	client.Do(req, nil)
+
+	var event map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &event); err != nil {
+		t.Fatalf("Should write an event as JSON. %q", out.String())
+	}
+	assertEquals(t, "level", event["level"], "info")
+	assertEquals(t, "operation", event["operation"], "PutObject")
+	assertEquals(t, "bucket", event["bucket"], "mybucket")
+	assertEquals(t, "key", event["key"], "dir/example")
+	assertEquals(t, "status", event["status"], float64(200))
+	assertEquals(t, "requestId", event["requestId"], "REQUEST0001")
+	if _, ok := event["durationMs"]; !ok {
+		t.Error("Should have the duration.")
+	}
+}
+
+func TestOperationName(t *testing.T) {
+	for _, v := range [][3]string{
+		{"GET", "https://storage-dag.iijgio.com/", "ListBuckets"},
+		{"GET", "https://storage-dag.iijgio.com/mybucket?prefix=foo", "ListObjects"},
+		{"PUT", "https://storage-dag.iijgio.com/mybucket?acl", "PutBucketAcl"},
+		{"POST", "https://storage-dag.iijgio.com/mybucket?delete", "DeleteObjects"},
+		{"GET", "https://storage-dag.iijgio.com/mybucket/foo", "GetObject"},
+		{"POST", "https://storage-dag.iijgio.com/mybucket/foo?uploads", "InitiateMultipartUpload"},
+		{"PUT", "https://storage-dag.iijgio.com/mybucket/foo?partNumber=1&uploadId=abc", "UploadPart"},
+		{"DELETE", "https://storage-dag.iijgio.com/mybucket/foo?uploadId=abc", "AbortMultipartUpload"},
+		{"PATCH", "https://storage-dag.iijgio.com/mybucket/foo", "PATCH"},
+	} {
+		req, _ := http.NewRequest(v[0], v[1], nil)
+		assertEquals(t, v[0]+" "+v[1], operationName(req), v[2])
+	}
+	req, _ := http.NewRequest("PUT", "https://storage-dag.iijgio.com/mybucket/bar", nil)
+	req.Header.Set("x-iijgio-copy-source", "/mybucket/foo")
+	assertEquals(t, "PUT Object - Copy", operationName(req), "CopyObject")
+}
+
 func TestListBucketsApi(t *testing.T) {
 	client, mock := newHTTPClientMock(t)
 	mockresp := &http.Response{
@@ -336,6 +435,231 @@ func TestDeleteBucketPolicyApi(t *testing.T) {
 	assertEquals(t, "Should return nil at normal end.", err, nil)
 }
 
+func TestGetBucketAclApi(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	mockresp := &http.Response{
+		Body: NewBodyWithString(`<?xml version="1.0" encoding="UTF-8"?>
+<AccessControlPolicy>
+  <Owner><ID>owner-id</ID><DisplayName>owner</DisplayName></Owner>
+  <AccessControlList>
+    <Grant>
+      <Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="CanonicalUser">
+        <ID>owner-id</ID><DisplayName>owner</DisplayName>
+      </Grantee>
+      <Permission>FULL_CONTROL</Permission>
+    </Grant>
+    <Grant>
+      <Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="Group">
+        <URI>http://acs.amazonaws.com/groups/global/AllUsers</URI>
+      </Grantee>
+      <Permission>READ</Permission>
+    </Grant>
+  </AccessControlList>
+</AccessControlPolicy>`),
+		StatusCode: 200,
+	}
+	mock.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		assertEquals(t, "Should request the acl sub-resource.", req.URL.RawQuery, "acl")
+	}).Return(mockresp, nil)
+
+	acl, err := client.GetBucketAcl("mybucket")
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	assertEquals(t, "Should parse the owner.", acl.Owner.ID, "owner-id")
+	assertEquals(t, "Should parse grants.", len(acl.Grants), 2)
+	assertEquals(t, "Should parse the grantee type.", acl.Grants[0].Grantee.Type, "CanonicalUser")
+	assertEquals(t, "Should parse the grantee type.", acl.Grants[1].Grantee.Type, "Group")
+	assertEquals(t, "Should parse the permission.", acl.Grants[1].Permission, "READ")
+}
+
+func TestPutObjectAclApi(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	mockresp := &http.Response{
+		Body:       NewEmptyBody(),
+		StatusCode: 200,
+	}
+	mock.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		assertEquals(t, "Should request the object acl.", req.URL.Path, "/mybucket/foo")
+		raw, _ := ioutil.ReadAll(req.Body)
+		if !strings.Contains(string(raw), `<Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="Group"><URI>`) {
+			t.Errorf("Should encode the grantee with xsi:type. %s", raw)
+		}
+	}).Return(mockresp, nil)
+
+	acl := &AccessControlPolicy{
+		Owner:  Owner{ID: "owner-id"},
+		Grants: []Grant{{Grantee: Grantee{Type: "Group", URI: "http://acs.amazonaws.com/groups/global/AllUsers"}, Permission: "READ"}},
+	}
+	err := client.PutObjectAcl("mybucket", "foo", acl)
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+}
+
+func TestPutBucketCannedAclApi(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	mockresp := &http.Response{
+		Body:       NewEmptyBody(),
+		StatusCode: 200,
+	}
+	mock.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		assertEquals(t, "Should set a canned acl header.", req.Header.Get("x-iijgio-acl"), "public-read")
+	}).Return(mockresp, nil)
+
+	err := client.PutBucketCannedAcl("mybucket", "public-read")
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	if err = client.PutBucketCannedAcl("mybucket", "public"); err == nil {
+		t.Error("Should return an error for an invalid canned acl.")
+	}
+}
+
+func TestGetBucketCorsApi(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	mockresp := &http.Response{
+		Body: NewBodyWithString(`<?xml version="1.0" encoding="UTF-8"?>
+<CORSConfiguration>
+  <CORSRule>
+    <AllowedOrigin>https://example.com</AllowedOrigin>
+    <AllowedMethod>GET</AllowedMethod>
+    <AllowedMethod>PUT</AllowedMethod>
+    <AllowedHeader>*</AllowedHeader>
+    <MaxAgeSeconds>3000</MaxAgeSeconds>
+  </CORSRule>
+</CORSConfiguration>`),
+		StatusCode: 200,
+	}
+	mock.EXPECT().Do(gomock.Any()).Return(mockresp, nil)
+
+	cors, err := client.GetBucketCors("mybucket")
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	assertEquals(t, "Should parse rules.", len(cors.Rules), 1)
+	assertEquals(t, "Should parse allowed methods.", strings.Join(cors.Rules[0].AllowedMethods, ","), "GET,PUT")
+	assertEquals(t, "Should parse max age.", cors.Rules[0].MaxAgeSeconds, 3000)
+}
+
+func TestPutBucketCorsApi(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	mockresp := &http.Response{
+		Body:       NewEmptyBody(),
+		StatusCode: 200,
+	}
+	mock.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		raw, _ := ioutil.ReadAll(req.Body)
+		sum := md5.Sum(raw)
+		assertEquals(t, "Should set Content-MD5 header.", req.Header.Get("Content-MD5"), base64.StdEncoding.EncodeToString(sum[:]))
+		assertEquals(t, "Should send the configuration.", string(raw),
+			"<CORSConfiguration><CORSRule><AllowedOrigin>*</AllowedOrigin><AllowedMethod>GET</AllowedMethod></CORSRule></CORSConfiguration>")
+	}).Return(mockresp, nil)
+
+	cors := &CORSConfiguration{Rules: []CORSRule{{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}}}}
+	err := client.PutBucketCors("mybucket", cors)
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+}
+
+func TestDeleteBucketCorsApi(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	mockresp := &http.Response{
+		Body:       NewEmptyBody(),
+		StatusCode: 204,
+	}
+	mock.EXPECT().Do(gomock.Any()).Return(mockresp, nil)
+
+	err := client.DeleteBucketCors("mybucket")
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+}
+
+func TestValidateCORSConfiguration(t *testing.T) {
+	valid := CORSRule{AllowedOrigins: []string{"https://*.example.com"}, AllowedMethods: []string{"GET", "HEAD"}, AllowedHeaders: []string{"*"}}
+	cors := &CORSConfiguration{Rules: []CORSRule{valid}}
+	assertEquals(t, "Should accept a valid configuration.", cors.Validate(), nil)
+	for _, rule := range []CORSRule{
+		{AllowedMethods: []string{"GET"}},
+		{AllowedOrigins: []string{"*"}},
+		{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"PATCH"}},
+		{AllowedOrigins: []string{"https://*.*.example.com"}, AllowedMethods: []string{"GET"}},
+		{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}, MaxAgeSeconds: -1},
+	} {
+		cors := &CORSConfiguration{Rules: []CORSRule{valid, rule}}
+		if err := cors.Validate(); err == nil || !strings.Contains(err.Error(), "rule #2") {
+			t.Errorf("Should reject an invalid rule. %v: %v", rule, err)
+		}
+	}
+	if err := new(CORSConfiguration).Validate(); err == nil {
+		t.Error("Should reject an empty configuration.")
+	}
+}
+
+func TestGetBucketWebsiteApi(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	mockresp := &http.Response{
+		Body: NewBodyWithString(`<?xml version="1.0" encoding="UTF-8"?>
+<WebsiteConfiguration>
+  <IndexDocument><Suffix>index.html</Suffix></IndexDocument>
+  <ErrorDocument><Key>404.html</Key></ErrorDocument>
+  <RoutingRules>
+    <RoutingRule>
+      <Condition><KeyPrefixEquals>docs/</KeyPrefixEquals></Condition>
+      <Redirect><ReplaceKeyPrefixWith>documents/</ReplaceKeyPrefixWith></Redirect>
+    </RoutingRule>
+  </RoutingRules>
+</WebsiteConfiguration>`),
+		StatusCode: 200,
+	}
+	mock.EXPECT().Do(gomock.Any()).Return(mockresp, nil)
+
+	website, err := client.GetBucketWebsite("mybucket")
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	assertEquals(t, "Should parse the index document.", website.IndexDocument, "index.html")
+	assertEquals(t, "Should parse the error document.", website.ErrorDocument, "404.html")
+	assertEquals(t, "Should parse routing rules.", len(website.RoutingRules), 1)
+	assertEquals(t, "Should parse the condition.", website.RoutingRules[0].Condition.KeyPrefixEquals, "docs/")
+	assertEquals(t, "Should parse the redirect.", website.RoutingRules[0].Redirect.ReplaceKeyPrefixWith, "documents/")
+}
+
+func TestPutBucketWebsiteApi(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	mockresp := &http.Response{
+		Body:       NewEmptyBody(),
+		StatusCode: 200,
+	}
+	mock.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		raw, _ := ioutil.ReadAll(req.Body)
+		assertEquals(t, "Should send the configuration.", string(raw),
+			"<WebsiteConfiguration><IndexDocument><Suffix>index.html</Suffix></IndexDocument></WebsiteConfiguration>")
+	}).Return(mockresp, nil)
+
+	err := client.PutBucketWebsite("mybucket", &WebsiteConfiguration{IndexDocument: "index.html"})
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+}
+
+func TestDeleteBucketWebsiteApi(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	mockresp := &http.Response{
+		Body:       NewEmptyBody(),
+		StatusCode: 204,
+	}
+	mock.EXPECT().Do(gomock.Any()).Return(mockresp, nil)
+
+	err := client.DeleteBucketWebsite("mybucket")
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+}
+
+func TestValidateWebsiteConfiguration(t *testing.T) {
+	for _, website := range []*WebsiteConfiguration{
+		{IndexDocument: "index.html", ErrorDocument: "error.html"},
+		{RedirectAllRequestsTo: &WebsiteRedirect{HostName: "example.com"}},
+	} {
+		assertEquals(t, "Should accept a valid configuration.", website.Validate(), nil)
+	}
+	for _, website := range []*WebsiteConfiguration{
+		{},
+		{IndexDocument: "docs/index.html"},
+		{IndexDocument: "index.html", RedirectAllRequestsTo: &WebsiteRedirect{HostName: "example.com"}},
+		{IndexDocument: "index.html", RoutingRules: []RoutingRule{{}}},
+	} {
+		if err := website.Validate(); err == nil {
+			t.Errorf("Should reject an invalid configuration. %v", website)
+		}
+	}
+}
+
 func TestListObjectsApi(t *testing.T) {
 	client, mock := newHTTPClientMock(t)
 	mockresp := &http.Response{
@@ -393,6 +717,25 @@ func TestPutObjectAtApi(t *testing.T) {
 	assertEquals(t, "Should return nil at normal end.", err, nil)
 }
 
+func TestPutObjectAtApiWithContentType(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	mockresp := &http.Response{
+		Body:       NewEmptyBody(),
+		StatusCode: 200,
+	}
+	mock.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		assertEquals(t, "Should keep Content-Type of the metadata.", req.Header.Get("Content-Type"), "text/html; charset=utf-8")
+		assertEquals(t, "Should set Cache-Control.", req.Header.Get("Cache-Control"), "no-cache")
+	}).Return(mockresp, nil)
+
+	f, openerr := os.OpenFile("test_file/test.txt", 0, 0644)
+	assertEquals(t, "Can not Open test File.", openerr, nil)
+	defer f.Close()
+	metadata := &ObjectMetadata{ContentType: "text/html; charset=utf-8", CacheControl: "no-cache"}
+	err := client.PutObjectAt("mybucket", "index.html", f, 0, 4, metadata)
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+}
+
 func TestGetObjectApi(t *testing.T) {
 	client, mock := newHTTPClientMock(t)
 	mockresp := &http.Response{
@@ -408,6 +751,143 @@ func TestGetObjectApi(t *testing.T) {
 	assertEquals(t, "Should return response body.", body, "dummy")
 }
 
+func TestGetObjectRangeApi(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	mockresp := &http.Response{
+		Body:       NewBodyWithString("dummy"),
+		StatusCode: 206,
+		Header:     http.Header{"Content-Range": {"bytes 10-14/100"}},
+	}
+	mock.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		assertEquals(t, "Should set a Range header.", req.Header.Get("Range"), "bytes=10-14")
+	}).Return(mockresp, nil)
+
+	r, cr, err := client.GetObjectRange("mybucket", "foo", 10, 5)
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	raw, _ := ioutil.ReadAll(r)
+	assertEquals(t, "Should return response body.", string(raw), "dummy")
+	assertEquals(t, "Should return the content range.", *cr, ContentRange{First: 10, Last: 14, Total: 100})
+}
+
+func TestGetObjectRangeApiSuffixRange(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	mockresp := &http.Response{
+		Body:       NewBodyWithString("dummy"),
+		StatusCode: 206,
+		Header:     http.Header{"Content-Range": {"bytes 95-99/100"}},
+	}
+	mock.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		assertEquals(t, "Should set a suffix Range header.", req.Header.Get("Range"), "bytes=-5")
+	}).Return(mockresp, nil)
+
+	_, cr, err := client.GetObjectRange("mybucket", "foo", -5, 0)
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	assertEquals(t, "Should return length of the content range.", cr.Length(), int64(5))
+}
+
+func TestGetObjectRangeApiWholeObject(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	mockresp := &http.Response{
+		Body:          NewBodyWithString("dummy"),
+		StatusCode:    200,
+		ContentLength: 5,
+	}
+	mock.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		assertEquals(t, "Should set an open-ended Range header.", req.Header.Get("Range"), "bytes=0-")
+	}).Return(mockresp, nil)
+
+	_, cr, err := client.GetObjectRange("mybucket", "foo", 0, 0)
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	assertEquals(t, "Should return the whole range.", cr.String(), "bytes 0-4/5")
+}
+
+func TestGetObjectRangeApiWholeObjectOfUnknownSize(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	mockresp := &http.Response{
+		Body:          NewBodyWithString("dummy"),
+		StatusCode:    200,
+		ContentLength: -1,
+	}
+	mock.EXPECT().Do(gomock.Any()).Return(mockresp, nil)
+
+	r, cr, err := client.GetObjectRange("mybucket", "foo", 10, 5)
+	if err == nil {
+		t.Error("Should return an error if the size is unknown.")
+	}
+	if r != nil || cr != nil {
+		t.Error("Should return neither a body nor a content range.", r, cr)
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	cr, err := ParseContentRange("bytes 0-1023/*")
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	assertEquals(t, "Should return unknown total size.", *cr, ContentRange{First: 0, Last: 1023, Total: -1})
+	for _, s := range []string{"", "bytes */100", "bytes 10-5/100", "items 0-1/2"} {
+		if _, err := ParseContentRange(s); err == nil {
+			t.Errorf("Should return an error. %q", s)
+		}
+	}
+}
+
+func TestCopyObjectApi(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	mockresp := &http.Response{
+		Body: NewBodyWithString(`<?xml version="1.0" encoding="UTF-8"?>
+<CopyObjectResult>
+  <LastModified>2009-10-12T17:50:30.000Z</LastModified>
+  <ETag>&quot;9b2cf535f27731c974343645a3985328&quot;</ETag>
+</CopyObjectResult>`),
+		StatusCode: 200,
+	}
+	mock.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		assertEquals(t, "Should set a copy source header.", req.Header.Get("x-iijgio-copy-source"), "/srcbucket/foo/%E6%97%A5%E6%9C%AC%E8%AA%9E")
+		assertEquals(t, "Should copy the metadata of the source object.", req.Header.Get("x-iijgio-metadata-directive"), "COPY")
+	}).Return(mockresp, nil)
+
+	res, err := client.CopyObject("srcbucket", "foo/日本語", "mybucket", "bar", nil)
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	assertEquals(t, "Should return ETag of the copied object.", res.ETag, `"9b2cf535f27731c974343645a3985328"`)
+	assertEquals(t, "Should return LastModified of the copied object.", res.LastModified, time.Date(2009, time.October, 12, 17, 50, 30, 0, time.UTC))
+}
+
+func TestCopyObjectApiWithMetadata(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	mockresp := &http.Response{
+		Body:       NewBodyWithString(`<CopyObjectResult><ETag>"dummy"</ETag></CopyObjectResult>`),
+		StatusCode: 200,
+	}
+	mock.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		assertEquals(t, "Should replace the metadata.", req.Header.Get("x-iijgio-metadata-directive"), "REPLACE")
+		assertEquals(t, "Should set the user metadata.", req.Header.Get("x-iijgio-meta-foo"), "bar")
+		assertEquals(t, "Should set a content type from the key.", req.Header.Get("Content-Type"), "text/plain")
+	}).Return(mockresp, nil)
+
+	metadata := new(ObjectMetadata)
+	metadata.AddUserMetadata("foo", "bar")
+	_, err := client.CopyObject("srcbucket", "foo", "mybucket", "bar.txt", metadata)
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+}
+
+func TestCopyObjectApiErrorInBody(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	mockresp := &http.Response{
+		Body: NewBodyWithString(`<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+  <Code>InternalError</Code>
+  <Message>We encountered an internal error. Please try again.</Message>
+  <RequestId>656c76696e6727732072657175657374</RequestId>
+</Error>`),
+		StatusCode: 200,
+	}
+	mock.EXPECT().Do(gomock.Any()).Return(mockresp, nil)
+
+	res, err := client.CopyObject("srcbucket", "foo", "mybucket", "bar", nil)
+	var foo *CopyObjectResult
+	assertEquals(t, "Should not return a result when failed to copy.", res, foo)
+	assertEquals(t, "Should return an error in the response body.", err.Error(), "200 InternalError We encountered an internal error. Please try again. (656c76696e6727732072657175657374)")
+}
+
 func TestDoesObjectExistApi(t *testing.T) {
 	client, mock := newHTTPClientMock(t)
 	mockresp := &http.Response{
@@ -549,6 +1029,381 @@ func TestUploadPartAtApi(t *testing.T) {
 	assertEquals(t, "Should return PartNumber 1 at normal end.", part.PartNumber, 1)
 }
 
+type rangeMatcher string
+
+func (m rangeMatcher) Matches(x interface{}) bool {
+	req := x.(*http.Request)
+	return req.Method == "GET" && req.Header.Get("Range") == string(m)
+}
+
+func (m rangeMatcher) String() string {
+	return "has Range: " + string(m)
+}
+
+func TestDownloadFileInParallel(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	client.Config.MultipartChunkSize = 4
+	client.env.Concurrency = 2
+	mock.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		assertEquals(t, "Should request a summary of the object.", req.Method, "HEAD")
+	}).Return(&http.Response{Body: NewEmptyBody(), StatusCode: 200, ContentLength: 10}, nil)
+	for _, part := range []struct{ byteRange, contentRange, body string }{
+		{"bytes=0-3", "bytes 0-3/10", "0123"},
+		{"bytes=4-7", "bytes 4-7/10", "4567"},
+		{"bytes=8-9", "bytes 8-9/10", "89"},
+	} {
+		mock.EXPECT().Do(rangeMatcher(part.byteRange)).Return(&http.Response{
+			Body:       NewBodyWithString(part.body),
+			StatusCode: 206,
+			Header:     http.Header{"Content-Range": {part.contentRange}},
+		}, nil)
+	}
+	fd, _ := ioutil.TempFile("", "dagtools")
+	defer os.Remove(fd.Name())
+	defer fd.Close()
+	fd.WriteString("garbage garbage")
+
+	err := client.DownloadFile("mybucket", "foo", fd)
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	raw, _ := ioutil.ReadFile(fd.Name())
+	assertEquals(t, "Should write each range at its offset.", string(raw), "0123456789")
+}
+
+func TestDownloadFileToPipe(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	client.Config.MultipartChunkSize = 4
+	mock.EXPECT().Do(gomock.Any()).Return(&http.Response{Body: NewEmptyBody(), StatusCode: 200, ContentLength: 10}, nil)
+	mock.EXPECT().Do(rangeMatcher("")).Return(&http.Response{
+		Body:          NewBodyWithString("0123456789"),
+		StatusCode:    200,
+		ContentLength: 10,
+	}, nil)
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	done := make(chan string)
+	go func() {
+		raw, _ := ioutil.ReadAll(r)
+		done <- string(raw)
+	}()
+
+	err = client.DownloadFile("mybucket", "foo", w)
+	w.Close()
+	assertEquals(t, "Should stream the object to a pipe.", err, nil)
+	assertEquals(t, "Should write the whole object.", <-done, "0123456789")
+	if _, err := os.Stat(DownloadStateFilename(w.Name())); !os.IsNotExist(err) {
+		t.Error("Should not create a download state file for a pipe.")
+	}
+}
+
+func TestDownloadFileModifiedObject(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	client.Config.MultipartChunkSize = 6
+	mock.EXPECT().Do(gomock.Any()).Return(&http.Response{Body: NewEmptyBody(), StatusCode: 200, ContentLength: 10}, nil)
+	mock.EXPECT().Do(rangeMatcher("bytes=0-5")).Return(&http.Response{
+		Body:       NewBodyWithString("012345"),
+		StatusCode: 206,
+		Header:     http.Header{"Content-Range": {"bytes 0-5/12"}},
+	}, nil)
+	mock.EXPECT().Do(rangeMatcher("bytes=6-9")).Return(&http.Response{
+		Body:       NewBodyWithString("6789"),
+		StatusCode: 206,
+		Header:     http.Header{"Content-Range": {"bytes 6-9/12"}},
+	}, nil)
+	fd, _ := ioutil.TempFile("", "dagtools")
+	defer os.Remove(fd.Name())
+	defer fd.Close()
+
+	err := client.DownloadFile("mybucket", "foo", fd)
+	if err == nil {
+		t.Error("Should return an error if the object was modified while downloading.")
+	}
+}
+
+func TestResumeDownloadFileSkipsDownloadedParts(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	client.Config.MultipartChunkSize = 4
+	lastModified := time.Date(2018, 8, 1, 12, 0, 0, 0, time.UTC)
+	mock.EXPECT().Do(gomock.Any()).Return(&http.Response{
+		Body:          NewEmptyBody(),
+		StatusCode:    200,
+		ContentLength: 10,
+		Header:        http.Header{"Etag": {`"abc"`}, "Last-Modified": {lastModified.Format(http.TimeFormat)}},
+	}, nil)
+	mock.EXPECT().Do(rangeMatcher("bytes=4-7")).Return(&http.Response{
+		Body:       NewBodyWithString("4567"),
+		StatusCode: 206,
+		Header:     http.Header{"Content-Range": {"bytes 4-7/10"}},
+	}, nil)
+	fd, _ := ioutil.TempFile("", "dagtools")
+	defer os.Remove(fd.Name())
+	defer fd.Close()
+	fd.WriteString("0123....89")
+	state := &downloadState{Bucket: "mybucket", Key: "foo", ETag: `"abc"`, LastModified: lastModified, Size: 10, ChunkSize: 4, Parts: []int{1, 3}}
+	state.save(DownloadStateFilename(fd.Name()))
+
+	err := client.ResumeDownloadFile("mybucket", "foo", fd)
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	raw, _ := ioutil.ReadFile(fd.Name())
+	assertEquals(t, "Should download only the missing part.", string(raw), "0123456789")
+	_, err = os.Stat(DownloadStateFilename(fd.Name()))
+	assertEquals(t, "Should remove the download state.", os.IsNotExist(err), true)
+	fi, _ := fd.Stat()
+	assertEquals(t, "Should set modification time of the file.", fi.ModTime().Unix(), lastModified.Unix())
+}
+
+func TestResumeDownloadFileFromEndOfFile(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	mock.EXPECT().Do(gomock.Any()).Return(&http.Response{
+		Body:          NewEmptyBody(),
+		StatusCode:    200,
+		ContentLength: 10,
+		Header:        http.Header{"Etag": {`"abc"`}},
+	}, nil)
+	mock.EXPECT().Do(rangeMatcher("bytes=6-9")).Return(&http.Response{
+		Body:       NewBodyWithString("6789"),
+		StatusCode: 206,
+		Header:     http.Header{"Content-Range": {"bytes 6-9/10"}},
+	}, nil)
+	fd, _ := ioutil.TempFile("", "dagtools")
+	defer os.Remove(fd.Name())
+	defer fd.Close()
+	fd.WriteString("012345")
+	state := &downloadState{Bucket: "mybucket", Key: "foo", ETag: `"abc"`, Size: 10, ChunkSize: client.Config.MultipartChunkSize}
+	state.save(DownloadStateFilename(fd.Name()))
+
+	err := client.ResumeDownloadFile("mybucket", "foo", fd)
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	raw, _ := ioutil.ReadFile(fd.Name())
+	assertEquals(t, "Should download the rest of the object.", string(raw), "0123456789")
+}
+
+func TestResumeDownloadFileModifiedObject(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	mock.EXPECT().Do(gomock.Any()).Return(&http.Response{
+		Body:          NewEmptyBody(),
+		StatusCode:    200,
+		ContentLength: 4,
+		Header:        http.Header{"Etag": {`"new"`}},
+	}, nil)
+	mock.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		assertEquals(t, "Should download the whole object.", req.Header.Get("Range"), "")
+	}).Return(&http.Response{Body: NewBodyWithString("abcd"), StatusCode: 200}, nil)
+	fd, _ := ioutil.TempFile("", "dagtools")
+	defer os.Remove(fd.Name())
+	defer fd.Close()
+	fd.WriteString("01")
+	state := &downloadState{Bucket: "mybucket", Key: "foo", ETag: `"old"`, Size: 4, ChunkSize: client.Config.MultipartChunkSize}
+	state.save(DownloadStateFilename(fd.Name()))
+
+	err := client.ResumeDownloadFile("mybucket", "foo", fd)
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	raw, _ := ioutil.ReadFile(fd.Name())
+	assertEquals(t, "Should download the object again.", string(raw), "abcd")
+}
+
+func TestResumeDownloadFileAlreadyDownloaded(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	lastModified := time.Date(2018, 8, 1, 12, 0, 0, 0, time.UTC)
+	mock.EXPECT().Do(gomock.Any()).Return(&http.Response{
+		Body:          NewEmptyBody(),
+		StatusCode:    200,
+		ContentLength: 4,
+		Header:        http.Header{"Last-Modified": {lastModified.Format(http.TimeFormat)}},
+	}, nil)
+	fd, _ := ioutil.TempFile("", "dagtools")
+	defer os.Remove(fd.Name())
+	defer fd.Close()
+	fd.WriteString("abcd")
+	os.Chtimes(fd.Name(), lastModified, lastModified)
+
+	err := client.ResumeDownloadFile("mybucket", "foo", fd)
+	assertEquals(t, "Should skip the downloaded file.", err, nil)
+}
+
+func TestWithContext(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cli := client.WithContext(ctx)
+	assertEquals(t, "Should not change the context of the original client.", client.Context(), context.Background())
+	mock.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		assertEquals(t, "Should bind the request to the context.", req.Context(), ctx)
+	}).Return(&http.Response{Body: NewEmptyBody(), StatusCode: 200}, nil)
+	_, err := cli.GetObjectSummary("mybucket", "foo")
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	cancel()
+}
+
+func TestWithContextCancelRetry(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	client.Config.Retry = 3
+	client.Config.RetryInterval = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	cli := client.WithContext(ctx)
+	mock.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+		cancel()
+	}).Return(&http.Response{Body: NewEmptyBody(), StatusCode: 500, Status: "500 Internal Server Error", Header: http.Header{}}, nil)
+	err := cli.DeleteObject("mybucket", "foo")
+	assertEquals(t, "Should stop retrying when the context is canceled.", err, context.Canceled)
+}
+
+func TestUploadCanceled(t *testing.T) {
+	client, _ := newHTTPClientMock(t)
+	client.Config.MultipartChunkSize = 4
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fd, _ := ioutil.TempFile("", "dagtools")
+	defer os.Remove(fd.Name())
+	defer fd.Close()
+	fd.WriteString("0123456789")
+	err := client.WithContext(ctx).Upload("mybucket", "foo", fd, nil)
+	assertEquals(t, "Should not upload anything after the context is canceled.", err, context.Canceled)
+}
+
+type methodMatcher string
+
+func (m methodMatcher) Matches(x interface{}) bool {
+	return x.(*http.Request).Method == string(m)
+}
+
+func (m methodMatcher) String() string {
+	return "is " + string(m) + " request"
+}
+
+func TestUploadFileNotAborted(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	client.Config.MultipartChunkSize = 4
+	client.Config.AbortOnFailure = false
+	mock.EXPECT().Do(methodMatcher("POST")).Return(&http.Response{
+		Body: NewBodyWithString(`<?xml version="1.0" encoding="UTF-8"?>
+<InitiateMultipartUploadResult xmlns="http://acs.dag.iijgio.com/doc/2006-03-01/">
+  <Bucket>mybucket</Bucket>
+  <Key>foo</Key>
+  <UploadId>dummy-upload-id</UploadId>
+</InitiateMultipartUploadResult>`),
+	}, nil)
+	mock.EXPECT().Do(methodMatcher("PUT")).Return(nil, errors.New("dummy")).Times(3)
+	fd, _ := ioutil.TempFile("", "dagtools")
+	defer os.Remove(fd.Name())
+	defer fd.Close()
+	fd.WriteString("0123456789")
+
+	err := client.UploadFile("mybucket", "foo", fd, nil)
+	e, ok := err.(*MultipartUploadError)
+	if !ok {
+		t.Fatalf("Should return MultipartUploadError. %v", err)
+	}
+	assertEquals(t, "Should return the upload to resume.", e.Upload.UploadID, "dummy-upload-id")
+}
+
+func TestUploadRemovesTemporaryFiles(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	dir, _ := ioutil.TempDir("", "dagtools")
+	defer os.RemoveAll(dir)
+	client.Config.TempDir = dir
+	mock.EXPECT().Do(methodMatcher("PUT")).Return(nil, errors.New("dummy"))
+
+	err := client.Upload("mybucket", "foo", strings.NewReader("0123456789"), nil)
+	if err == nil {
+		t.Error("Should return an error.")
+	}
+	files, _ := ioutil.ReadDir(dir)
+	assertEquals(t, "Should remove temporary files.", len(files), 0)
+}
+
+func TestUploadsWithFailedParts(t *testing.T) {
+	var aborted, completed int32
+	client, server := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		q := r.URL.Query()
+		switch {
+		case r.Method == "POST" && q.Get("uploadId") == "":
+			w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>mybucket</Bucket><Key>foo</Key><UploadId>1</UploadId></InitiateMultipartUploadResult>`))
+		case r.Method == "POST":
+			atomic.AddInt32(&completed, 1)
+			w.Write([]byte(`<CompleteMultipartUploadResult><Bucket>mybucket</Bucket><Key>foo</Key></CompleteMultipartUploadResult>`))
+		case r.Method == "GET":
+			w.Write([]byte(`<ListPartsResult><Bucket>mybucket</Bucket><Key>foo</Key><UploadId>1</UploadId></ListPartsResult>`))
+		case r.Method == "PUT" && (q.Get("partNumber") == "2" || q.Get("partNumber") == "3"):
+			// fail concurrently while the other parts are still being uploaded
+			w.WriteHeader(400)
+		case r.Method == "PUT":
+			time.Sleep(20 * time.Millisecond)
+			w.Header().Set("ETag", `"dummy"`)
+		case r.Method == "DELETE":
+			atomic.AddInt32(&aborted, 1)
+			w.WriteHeader(204)
+		}
+	})
+	defer server.Close()
+	client.Config.MultipartChunkSize = 4
+	client.Config.Retry = 0
+	client.env.Concurrency = 3
+	fd, _ := ioutil.TempFile("", "dagtools")
+	defer os.Remove(fd.Name())
+	defer fd.Close()
+	fd.WriteString("01234567890123456789")
+	for name, upload := range map[string]func() error{
+		"Upload": func() error {
+			return client.Upload("mybucket", "foo", iotest.OneByteReader(strings.NewReader("01234567890123456789")), nil)
+		},
+		"UploadFile": func() error {
+			return client.UploadFile("mybucket", "foo", fd, nil)
+		},
+		"ResumeUploadFile": func() error {
+			return client.ResumeUploadFile("mybucket", "foo", "1", fd, nil)
+		},
+	} {
+		atomic.StoreInt32(&aborted, 0)
+		atomic.StoreInt32(&completed, 0)
+		if err := upload(); err == nil {
+			t.Errorf("%s should return an error.", name)
+		}
+		assertEquals(t, name+" should abort the upload.", atomic.LoadInt32(&aborted), int32(1))
+		assertEquals(t, name+" should not complete the upload.", atomic.LoadInt32(&completed), int32(0))
+	}
+}
+
+func TestReuseConnections(t *testing.T) {
+	var conns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}))
+	server.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+	client := newMock()
+	client.Config.Endpoint = strings.TrimPrefix(server.URL, "http://")
+	client.Config.Secure = false
+
+	for i := 0; i < 3; i++ {
+		err := client.PutBucket("mybucket")
+		assertEquals(t, "Should return nil at normal end.", err, nil)
+	}
+	err := client.WithContext(context.Background()).PutBucket("mybucket")
+	assertEquals(t, "Should return nil at normal end.", err, nil)
+	assertEquals(t, "Should reuse a connection.", atomic.LoadInt32(&conns), int32(1))
+}
+
+func TestHTTPConnectionConfig(t *testing.T) {
+	e := newMockEnvironment()
+	e.Concurrency = 8
+	e.Config.Set("storage", "dialTimeout", "5000")
+	e.Config.Set("storage", "responseHeaderTimeout", "60000")
+	cli, _ := NewStorageClient(&e)
+	config := cli.(*DefaultStorageClient).Config
+	assertEquals(t, "Should keep idle connections for concurrent transfers.", config.MaxIdleConnsPerHost, 8)
+	assertEquals(t, "Should use the default value.", config.IdleConnTimeout, 90*time.Second)
+	assertEquals(t, "Should read dialTimeout.", config.DialTimeout, 5*time.Second)
+	assertEquals(t, "Should read responseHeaderTimeout.", config.ResponseHeaderTimeout, time.Minute)
+}
+
 func TestListBucketsApiHTTPErr(t *testing.T) {
 	client, mock := newHTTPClientMock(t)
 	mock.EXPECT().Do(gomock.Any()).Return(nil, errors.New("dummy"))
@@ -965,3 +1820,65 @@ func NewEmptyBody() *DummyResponseBody {
 func NewBodyWithString(data string) *DummyResponseBody {
 	return &DummyResponseBody{data: strings.NewReader(data)}
 }
+
+func TestNewStorageClientWithProfile(t *testing.T) {
+	e := newMockEnvironment()
+	e.Config.Set("profile staging", "endpoint", "staging.example.com")
+	e.Config.Set("profile staging", "accessKeyId", "STAGING0000000000000")
+	e.Config.Set("profile staging", "multipartChunkSize", "5242880")
+	e.Config.Set("profile staging", "retry", "5")
+	e.Profile = "staging"
+	if err := e.Init(); err != nil {
+		t.Fatal(err)
+	}
+	_client, _ := NewStorageClient(&e)
+	client := _client.(*DefaultStorageClient)
+	assertEquals(t, "Should use the endpoint of the profile.", client.Config.Endpoint, "staging.example.com")
+	accessKeyID, secretAccessKey, _ := client.accessKeys()
+	assertEquals(t, "Should use the access key of the profile.", accessKeyID, "STAGING0000000000000")
+	assertEquals(t, "Should not inherit the values of [storage].", secretAccessKey, "")
+	assertEquals(t, "Should use the chunk size of the profile.", client.Config.MultipartChunkSize, int64(5242880))
+	assertEquals(t, "Should use the retry of the profile.", client.Config.Retry, 5)
+}
+
+func TestNewStorageClientWithInvalidValue(t *testing.T) {
+	e := newMockEnvironment()
+	e.Config.Set("storage", "multipartChunkSize", "5MB")
+	_client, _ := NewStorageClient(&e)
+	client := _client.(*DefaultStorageClient)
+	assertEquals(t, "Should use the default chunk size.", client.Config.MultipartChunkSize, int64(defaultMultipartChunkSize))
+	if _, err := client.PresignURL("GET", "mybucket", "foo", time.Now(), nil); err == nil || !strings.Contains(err.Error(), "multipartChunkSize") {
+		t.Errorf("Should return an error of the invalid value. %v", err)
+	}
+}
+
+func TestStats(t *testing.T) {
+	client, mock := newHTTPClientMock(t)
+	client.Config.Retry = 1
+	client.Config.RetryInterval = time.Millisecond
+	gomock.InOrder(
+		mock.EXPECT().Do(gomock.Any()).Return(&http.Response{Body: NewEmptyBody(), StatusCode: 503, Status: "503 Service Unavailable", Header: http.Header{}}, nil),
+		mock.EXPECT().Do(gomock.Any()).Do(func(req *http.Request) {
+			ioutil.ReadAll(req.Body)
+		}).Return(&http.Response{Body: ioutil.NopCloser(strings.NewReader("")), StatusCode: 200, Header: http.Header{}}, nil),
+		mock.EXPECT().Do(gomock.Any()).Return(&http.Response{Body: ioutil.NopCloser(strings.NewReader("0123456789")), StatusCode: 200, Header: http.Header{}}, nil),
+	)
+	fd, _ := ioutil.TempFile("", "dagtools")
+	defer os.Remove(fd.Name())
+	defer fd.Close()
+	fd.WriteString("0123456789")
+	if err := client.PutObject("mybucket", "foo", fd, nil); err != nil {
+		t.Fatal(err)
+	}
+	r, err := client.GetObject("mybucket", "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioutil.ReadAll(r)
+	r.Close()
+	s := client.Stats.Summary()
+	assertEquals(t, "Should count the requests.", len(s.Requests), 3)
+	assertEquals(t, "Should count the retries.", s.Retries, int64(1))
+	assertEquals(t, "Should count the bytes sent.", s.BytesSent, int64(10))
+	assertEquals(t, "Should count the bytes received.", s.BytesReceived, int64(10))
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/iij/dagtools/client"
+	"github.com/iij/dagtools/env"
+)
+
+var (
+	aclSubCommands = map[string]bool{
+		"put": true,
+		"cat": true,
+	}
+)
+
+type aclCommand struct {
+	env    *env.Environment
+	cli    client.StorageClient
+	opts   *flag.FlagSet
+	canned string
+}
+
+func (c *aclCommand) Description() string {
+	return "manage an access control list of a bucket or an object (put, cat)"
+}
+
+func (c *aclCommand) Usage() string {
+	return fmt.Sprintf(`Command Usage:
+  acl cat <bucket>[:<key>]
+  acl put <bucket>[:<key>] <file>
+  acl put <bucket>[:<key>] < <file>
+  acl put -canned=<acl> <bucket>[:<key>]
+
+Canned ACLs:
+  %s
+
+Options:
+%s`, strings.Join(client.CannedACLs, ", "), OptionUsage(c.opts))
+}
+
+func (c *aclCommand) Init(env *env.Environment) (err error) {
+	c.env = env
+	c.cli, _ = client.NewStorageClient(env)
+	opts := flag.NewFlagSet("acl", flag.ExitOnError)
+	opts.StringVar(&c.canned, "canned", "", "put a canned acl (e.g. private, public-read)")
+	opts.Usage = func() {
+		fmt.Fprintln(os.Stdout, c.Usage())
+	}
+	c.opts = opts
+	return
+}
+
+func (c *aclCommand) Run(args []string) (err error) {
+	if len(args) < 1 {
+		return ErrArgument
+	}
+	command := args[0]
+	if !aclSubCommands[command] {
+		return fmt.Errorf("acl's sub-command not found: %q", command)
+	}
+	c.opts.Parse(args[1:])
+	argv := c.opts.Args()
+	if len(argv) < 1 {
+		return ErrArgument
+	}
+	bucket, key := argv[0], ""
+	if strings.Contains(bucket, ":") {
+		bucket, key, _ = splitResource(bucket)
+	}
+	if bucket == "" {
+		return ErrArgument
+	}
+	switch command {
+	case "put":
+		if c.canned != "" {
+			if len(argv) != 1 {
+				return ErrArgument
+			}
+			if !client.IsCannedACL(c.canned) {
+				return fmt.Errorf("invalid canned acl: %q", c.canned)
+			}
+			if key != "" {
+				return c.cli.PutObjectCannedAcl(bucket, key, c.canned)
+			}
+			return c.cli.PutBucketCannedAcl(bucket, c.canned)
+		}
+		acl, err := c.readAcl(argv[1:])
+		if err != nil {
+			return err
+		}
+		if key != "" {
+			return c.cli.PutObjectAcl(bucket, key, acl)
+		}
+		return c.cli.PutBucketAcl(bucket, acl)
+	case "cat":
+		if c.canned != "" || len(argv) != 1 {
+			return ErrArgument
+		}
+		var acl *client.AccessControlPolicy
+		if key != "" {
+			acl, err = c.cli.GetObjectAcl(bucket, key)
+		} else {
+			acl, err = c.cli.GetBucketAcl(bucket)
+		}
+		if err != nil {
+			return err
+		}
+		b, err := xml.MarshalIndent(acl, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(xml.Header + string(b))
+	}
+	return
+}
+
+// readAcl reads an access control list in XML from the file or stdin.
+func (c *aclCommand) readAcl(argv []string) (acl *client.AccessControlPolicy, err error) {
+	in, err := openInput(argv)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	acl = new(client.AccessControlPolicy)
+	if err = xml.NewDecoder(in).Decode(acl); err != nil {
+		return nil, fmt.Errorf("invalid acl: %v", err)
+	}
+	if len(acl.Grants) == 0 {
+		return nil, errors.New("invalid acl: no grant is specified")
+	}
+	return acl, nil
+}
+
+func init() {
+	Commands.Register(new(aclCommand), "acl")
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/iij/dagtools/client"
+	"github.com/iij/dagtools/env"
+	"github.com/iij/dagtools/ini"
+	"github.com/golang/mock/gomock"
+)
+
+func TestAclUsage(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(aclCommand)
+	c.Init(&e)
+	usage := c.Usage()
+	if !strings.HasPrefix(usage, "Command Usage:") {
+		t.Errorf("Failed to get an acl command usage. usage: %q", usage)
+	}
+}
+
+func TestCatBucketAcl(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(aclCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	mock.EXPECT().GetBucketAcl("mybucket").Return(nil, errors.New("ok"))
+	c.cli = mock
+	err := c.Run(parseArgs("cat mybucket"))
+	if err == nil || err.Error() != "ok" {
+		t.Error("unknown error:", err)
+	}
+}
+
+func TestCatObjectAcl(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(aclCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	mock.EXPECT().GetObjectAcl("mybucket", "foo/bar").Return(&client.AccessControlPolicy{}, nil)
+	c.cli = mock
+	if err := c.Run(parseArgs("cat mybucket:foo/bar")); err != nil {
+		t.Error("unknown error:", err)
+	}
+}
+
+func TestPutAclFromFile(t *testing.T) {
+	fd, err := ioutil.TempFile("", "acl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(fd.Name())
+	fd.WriteString(`<AccessControlPolicy>
+  <Owner><ID>owner-id</ID></Owner>
+  <AccessControlList>
+    <Grant>
+      <Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="CanonicalUser"><ID>owner-id</ID></Grantee>
+      <Permission>FULL_CONTROL</Permission>
+    </Grant>
+  </AccessControlList>
+</AccessControlPolicy>`)
+	fd.Close()
+
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(aclCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	mock.EXPECT().PutBucketAcl("mybucket", gomock.Any()).Do(func(bucket string, acl *client.AccessControlPolicy) {
+		if len(acl.Grants) != 1 || acl.Grants[0].Permission != "FULL_CONTROL" {
+			t.Errorf("Should pass the acl read from the file. %v", acl.Grants)
+		}
+	}).Return(nil)
+	c.cli = mock
+	if err := c.Run(parseArgs("put mybucket " + fd.Name())); err != nil {
+		t.Error("unknown error:", err)
+	}
+}
+
+func TestPutAclInvalidFile(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(aclCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	c.cli = client.NewMockStorageClient(ctrl)
+	err := c.Run(parseArgs("put mybucket test_files/test-00.txt"))
+	if err == nil || !strings.HasPrefix(err.Error(), "invalid acl") {
+		t.Error("unknown error:", err)
+	}
+}
+
+func TestPutCannedAcl(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(aclCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	mock.EXPECT().PutObjectCannedAcl("mybucket", "foo", "public-read").Return(nil)
+	c.cli = mock
+	if err := c.Run(parseArgs("put -canned public-read mybucket:foo")); err != nil {
+		t.Error("unknown error:", err)
+	}
+}
+
+func TestPutInvalidCannedAcl(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(aclCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	c.cli = client.NewMockStorageClient(ctrl)
+	err := c.Run(parseArgs("put -canned public mybucket"))
+	if err == nil || err.Error() != `invalid canned acl: "public"` {
+		t.Error("unknown error:", err)
+	}
+}
+
+func TestAclUnknownCommand(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(aclCommand)
+	c.Init(&e)
+	err := c.Run(parseArgs("rm mybucket"))
+	if err == nil || err.Error() != `acl's sub-command not found: "rm"` {
+		t.Error("unknown error:", err)
+	}
+}
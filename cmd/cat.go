@@ -2,6 +2,9 @@ package cmd
 
 import (
 	"bufio"
+	"flag"
+	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -10,8 +13,10 @@ import (
 )
 
 type catCommand struct {
-	env *env.Environment
-	cli client.StorageClient
+	env       *env.Environment
+	cli       client.StorageClient
+	opts      *flag.FlagSet
+	byteRange string
 }
 
 func (c *catCommand) Description() string {
@@ -19,14 +24,25 @@ func (c *catCommand) Description() string {
 }
 
 func (c *catCommand) Usage() string {
-	return `Command Usage:
+	return fmt.Sprintf(`Command Usage:
   cat <bucket>:<key>
-`
+  cat -range=<first>-<last> <bucket>:<key>
+  cat -range=<first>- <bucket>:<key>
+  cat -range=-<length> <bucket>:<key>
+
+Options:
+%s`, OptionUsage(c.opts))
 }
 
 func (c *catCommand) Init(env *env.Environment) (err error) {
 	c.env = env
 	c.cli, _ = client.NewStorageClient(env)
+	opts := flag.NewFlagSet("cat", flag.ExitOnError)
+	opts.StringVar(&c.byteRange, "range", "", "print only the specified byte range (e.g., 0-1023, 1024-, -1024)")
+	opts.Usage = func() {
+		fmt.Fprintln(os.Stdout, c.Usage())
+	}
+	c.opts = opts
 	return
 }
 
@@ -35,10 +51,12 @@ func (c *catCommand) Run(args []string) (err error) {
 		bucket = ""
 		key    = ""
 	)
-	if len(args) != 1 {
+	c.opts.Parse(args)
+	argv := c.opts.Args()
+	if len(argv) != 1 {
 		return ErrArgument
 	}
-	slice := strings.Split(args[0], ":")
+	slice := strings.Split(argv[0], ":")
 	if len(slice) < 2 {
 		return ErrArgument
 	}
@@ -51,13 +69,25 @@ func (c *catCommand) Run(args []string) (err error) {
 }
 
 func (c *catCommand) exec(bucket, key string) (err error) {
-	r, err := c.cli.GetObject(bucket, key)
+	var (
+		r           io.ReadCloser
+		off, length int64
+	)
+	if c.byteRange != "" {
+		if off, length, err = parseRange(c.byteRange); err != nil {
+			return err
+		}
+		r, _, err = c.cli.GetObjectRange(bucket, key, off, length)
+	} else {
+		r, err = c.cli.GetObject(bucket, key)
+	}
 	if err != nil {
 		if strings.HasPrefix(key, "/") {
 			return c.exec(bucket, strings.TrimLeft(key, "/"))
 		}
 		return err
 	}
+	defer r.Close()
 	in := bufio.NewReader(r)
 	out := bufio.NewWriter(os.Stdout)
 	in.WriteTo(out)
@@ -81,3 +81,28 @@ func TestCatAnObjectWithSpecialKey(t *testing.T) {
 		t.Error("Error message was not match. ", err)
 	}
 }
+
+func TestCatAnObjectWithRange(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(catCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	mock.EXPECT().GetObjectRange("mybucket", "foo/bar", int64(0), int64(1024)).Return(nil, nil, errors.New("dummy"))
+	mock.EXPECT().GetObjectRange("mybucket", "foo/bar", int64(-1024), int64(0)).Return(nil, nil, errors.New("dummy"))
+	c.cli = mock
+	err := c.Run(parseArgs("-range 0-1023 mybucket:foo/bar"))
+	if err == nil || err.Error() != "dummy" {
+		t.Errorf("Error message was not match. dummy != %v", err)
+	}
+	err = c.Run(parseArgs("-range -1024 mybucket:foo/bar"))
+	if err == nil || err.Error() != "dummy" {
+		t.Errorf("Error message was not match. dummy != %v", err)
+	}
+	err = c.Run(parseArgs("-range 10-5 mybucket:foo/bar"))
+	if err == nil {
+		t.Error("Failed to get an error.")
+	}
+}
@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iij/dagtools/env"
+	"github.com/iij/dagtools/ini"
+)
+
+var (
+	configSubCommands = map[string]bool{
+		"init":     true,
+		"get":      true,
+		"set":      true,
+		"validate": true,
+	}
+)
+
+// sectionSchema returns the options of the section. [dagrin] and profile sections have the same options as [storage].
+func sectionSchema(section string) map[string]env.ConfigValidator {
+	if section == "dagrin" || strings.HasPrefix(section, "profile ") || strings.HasPrefix(section, "storage:") {
+		return env.StorageConfigSchema
+	}
+	return env.ConfigSchema[section]
+}
+
+// validateOption returns an error if the option is unknown or the value is invalid.
+func validateOption(section, key, value string) error {
+	schema := sectionSchema(section)
+	if schema == nil {
+		return fmt.Errorf("unknown section: [%s]", section)
+	}
+	validate, ok := schema[key]
+	if !ok {
+		for name := range schema {
+			if strings.EqualFold(name, key) {
+				return fmt.Errorf("unknown key: %q (did you mean %q?)", key, name)
+			}
+		}
+		return fmt.Errorf("unknown key: %q", key)
+	}
+	return validate(value)
+}
+
+type configCommand struct {
+	env     *env.Environment
+	opts    *flag.FlagSet
+	in      *bufio.Reader
+	force   bool
+	offline bool
+	timeout time.Duration
+}
+
+func (c *configCommand) Description() string {
+	return "manage the configuration file (init, get, set, validate)"
+}
+
+func (c *configCommand) Usage() string {
+	return fmt.Sprintf(`Command Usage:
+  config init [-force]
+  config get <section>.<key>
+  config set <section>.<key> <value>
+  config validate [-offline] [-timeout=<duration>]
+
+The configuration file is specified by the -f option (default: ./dagtools.ini or /etc/dagtools.ini).
+A section with a space or a colon is also accepted, e.g. "config get profile staging.endpoint".
+
+Options:
+%s`, OptionUsage(c.opts))
+}
+
+func (c *configCommand) Init(env *env.Environment) (err error) {
+	c.env = env
+	c.in = bufio.NewReader(os.Stdin)
+	opts := flag.NewFlagSet("config", flag.ExitOnError)
+	opts.BoolVar(&c.force, "force", false, "overwrite the existing file (init)")
+	opts.BoolVar(&c.offline, "offline", false, "do not check connections to the endpoints (validate)")
+	opts.DurationVar(&c.timeout, "timeout", 5*time.Second, "timeout of connections to the endpoints (validate)")
+	opts.Usage = func() {
+		fmt.Fprintln(os.Stdout, c.Usage())
+	}
+	c.opts = opts
+	return
+}
+
+func (c *configCommand) Run(args []string) (err error) {
+	if len(args) < 1 {
+		return ErrArgument
+	}
+	command := args[0]
+	if !configSubCommands[command] {
+		return fmt.Errorf("config's sub-command not found: %q", command)
+	}
+	c.opts.Parse(args[1:])
+	argv := c.opts.Args()
+	switch command {
+	case "init":
+		if len(argv) != 0 {
+			return ErrArgument
+		}
+		return c.initFile(c.env.Config.Filename)
+	case "get":
+		if len(argv) != 1 {
+			return ErrArgument
+		}
+		section, key, err := splitConfigKey(argv[0])
+		if err != nil {
+			return err
+		}
+		s := c.env.Config.Sections[section]
+		value, ok := s[key]
+		if !ok {
+			return fmt.Errorf("not set: %s.%s", section, key)
+		}
+		fmt.Println(value)
+	case "set":
+		if len(argv) != 2 {
+			return ErrArgument
+		}
+		section, key, err := splitConfigKey(argv[0])
+		if err != nil {
+			return err
+		}
+		if err = validateOption(section, key, argv[1]); err != nil {
+			return err
+		}
+		return c.set(c.env.Config.Filename, section, key, argv[1])
+	case "validate":
+		if len(argv) != 0 {
+			return ErrArgument
+		}
+		return c.validate()
+	}
+	return
+}
+
+// splitConfigKey splits "section.key" at the last dot.
+func splitConfigKey(s string) (section, key string, err error) {
+	i := strings.LastIndex(s, ".")
+	if i <= 0 || i == len(s)-1 {
+		return "", "", fmt.Errorf("invalid key: %q (<section>.<key>)", s)
+	}
+	return s[:i], s[i+1:], nil
+}
+
+// set changes the value in the file keeping the comments and the order of options.
+func (c *configCommand) set(filename, section, key, value string) error {
+	doc, err := ini.LoadDocument(filename)
+	if os.IsNotExist(err) {
+		doc, err = ini.ReadDocument(strings.NewReader(""))
+	}
+	if err != nil {
+		return err
+	}
+	doc.Set(section, key, value)
+	if err = doc.Save(filename, 0600); err != nil {
+		return err
+	}
+	if c.env.Verbose {
+		fmt.Fprintf(os.Stderr, "set: %s.%s (%s)\n", section, key, filename)
+	}
+	return nil
+}
+
+// initFile asks the settings and creates a configuration file readable only by the owner.
+func (c *configCommand) initFile(filename string) (err error) {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if c.force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	} else if _, err := os.Stat(filename); err == nil {
+		return fmt.Errorf("%s already exists. use -force to overwrite it", filename)
+	}
+	values := []struct {
+		section, key, prompt, value string
+	}{
+		{"storage", "endpoint", "Endpoint", "storage-dag.iijgio.com"},
+		{"storage", "accessKeyId", "Access Key ID", ""},
+		{"storage", "secretAccessKey", "Secret Access Key", ""},
+		{"storage", "secure", "Use HTTPS (true, false)", "true"},
+		{"dagtools", "concurrency", "Concurrency", "1"},
+		{"dagtools", "proxy", "HTTP Proxy", ""},
+	}
+	for i := range values {
+		v := &values[i]
+		if v.value, err = c.ask(v.prompt, v.value, env.ConfigSchema[v.section][v.key]); err != nil {
+			return err
+		}
+	}
+	doc, _ := ini.ReadDocument(strings.NewReader(""))
+	doc.Set("dagtools", "debug", "false")
+	doc.Set("dagtools", "verbose", "true")
+	for _, v := range values {
+		if v.section == "dagtools" {
+			doc.Set(v.section, v.key, v.value)
+		}
+	}
+	doc.Set("logging", "type", "none")
+	for _, v := range values {
+		if v.section == "storage" {
+			doc.Set(v.section, v.key, v.value)
+		}
+	}
+	out, err := os.OpenFile(filename, flags, 0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if err = out.Chmod(0600); err != nil {
+		return err
+	}
+	if _, err = doc.WriteTo(out); err != nil {
+		return err
+	}
+	fmt.Printf("created: %s\n", filename)
+	return nil
+}
+
+// ask prints the prompt and reads a value until it is valid. An empty input means the default value.
+func (c *configCommand) ask(prompt, defaultValue string, validate env.ConfigValidator) (string, error) {
+	for {
+		if defaultValue != "" {
+			fmt.Printf("%s [%s]: ", prompt, defaultValue)
+		} else {
+			fmt.Printf("%s: ", prompt)
+		}
+		line, err := c.in.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			if err == io.EOF {
+				fmt.Println()
+				return "", errors.New("config init was canceled")
+			}
+			return "", err
+		}
+		value := strings.TrimSpace(line)
+		if value == "" {
+			value = defaultValue
+		}
+		if err := validate(value); err != nil {
+			fmt.Println(err)
+			continue
+		}
+		return value, nil
+	}
+}
+
+// validate reports unknown sections and keys, invalid values and unreachable endpoints.
+func (c *configCommand) validate() error {
+	config := c.env.Config
+	var (
+		problems []string
+		sections []string
+	)
+	for name := range config.Sections {
+		sections = append(sections, name)
+	}
+	sort.Strings(sections)
+	for _, section := range sections {
+		if sectionSchema(section) == nil {
+			problems = append(problems, fmt.Sprintf("unknown section: [%s]", section))
+			continue
+		}
+		var keys []string
+		for key := range config.Sections[section] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := validateOption(section, key, config.Sections[section][key]); err != nil {
+				problems = append(problems, fmt.Sprintf("[%s] %v", section, err))
+			}
+		}
+	}
+	if !c.offline {
+		for _, section := range sections {
+			if sectionSchema(section)["endpoint"] == nil {
+				continue
+			}
+			if err := c.checkEndpoint(config.Section(section)); err != nil {
+				problems = append(problems, fmt.Sprintf("[%s] %v", section, err))
+			}
+		}
+	}
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d problem(s) found in %s", len(problems), config.Filename)
+	}
+	fmt.Printf("%s: OK\n", config.Filename)
+	return nil
+}
+
+// checkEndpoint connects to the endpoint of the section (or the proxy if specified).
+func (c *configCommand) checkEndpoint(s *ini.Section) error {
+	endpoint := s.Get("endpoint", "storage-dag.iijgio.com")
+	port := "443"
+	if !s.GetBool("secure", true) {
+		port = "80"
+	}
+	addr := endpoint
+	if _, _, err := net.SplitHostPort(endpoint); err != nil {
+		addr = net.JoinHostPort(endpoint, port)
+	}
+	if proxy := c.env.Config.Get("dagtools", "proxy", ""); proxy != "" {
+		if u, err := url.Parse(proxy); err == nil && u.Host != "" {
+			addr = u.Host
+			if u.Port() == "" {
+				addr = net.JoinHostPort(u.Hostname(), "80")
+			}
+		}
+	}
+	conn, err := net.DialTimeout("tcp", addr, c.timeout)
+	if err != nil {
+		return fmt.Errorf("unreachable endpoint: %s (%v)", addr, err)
+	}
+	conn.Close()
+	return nil
+}
+
+func init() {
+	Commands.Register(new(configCommand), "config")
+}
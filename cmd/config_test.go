@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iij/dagtools/env"
+	"github.com/iij/dagtools/ini"
+)
+
+func newConfigCommand(t *testing.T, content string) (*configCommand, string) {
+	dir, err := ioutil.TempDir("", "dagtools-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "dagtools.ini")
+	config := ini.Config{Filename: filename, Sections: make(map[string]ini.Section)}
+	if content != "" {
+		ioutil.WriteFile(filename, []byte(content), 0600)
+		if config, err = ini.LoadFile(filename); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e := env.Environment{Config: &config}
+	e.Init()
+	c := new(configCommand)
+	c.Init(&e)
+	return c, filename
+}
+
+func TestConfigUsage(t *testing.T) {
+	c, filename := newConfigCommand(t, "")
+	defer os.RemoveAll(filepath.Dir(filename))
+	usage := c.Usage()
+	if !strings.HasPrefix(usage, "Command Usage:") {
+		t.Errorf("Failed to get a config command usage. usage: %q", usage)
+	}
+}
+
+func TestConfigInit(t *testing.T) {
+	c, filename := newConfigCommand(t, "")
+	defer os.RemoveAll(filepath.Dir(filename))
+	c.in = bufio.NewReader(strings.NewReader("\nSAMPLE00000000000000\nsecret#key\nyes\nfalse\n4\n\n"))
+	if err := c.Run(parseArgs("init")); err != nil {
+		t.Fatal("unknown error:", err)
+	}
+	stat, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Mode().Perm() != 0600 {
+		t.Errorf("The file must be readable only by the owner. %v", stat.Mode())
+	}
+	config, err := ini.LoadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range [][3]string{
+		{"storage", "endpoint", "storage-dag.iijgio.com"},
+		{"storage", "accessKeyId", "SAMPLE00000000000000"},
+		{"storage", "secretAccessKey", "secret#key"},
+		{"storage", "secure", "false"},
+		{"dagtools", "concurrency", "4"},
+	} {
+		if value := config.Get(v[0], v[1], ""); value != v[2] {
+			t.Errorf("%s.%s should be %q: %q", v[0], v[1], v[2], value)
+		}
+	}
+	if err := c.Run(parseArgs("init")); err == nil {
+		t.Error("Should not overwrite the existing file without -force.")
+	}
+}
+
+func TestConfigGetAndSet(t *testing.T) {
+	c, filename := newConfigCommand(t, "# dagtools\n[storage]\nendpoint = storage-dag.iijgio.com\nretry = 2 # number of retries\n")
+	defer os.RemoveAll(filepath.Dir(filename))
+	if err := c.Run(parseArgs("get storage.endpoint")); err != nil {
+		t.Error("unknown error:", err)
+	}
+	if err := c.Run(parseArgs("get storage.region")); err == nil {
+		t.Error("Should return an error if the key is not set.")
+	}
+	if err := c.Run(parseArgs("set storage.retry 5")); err != nil {
+		t.Error("unknown error:", err)
+	}
+	if err := c.Run(parseArgs("set storage.multipartChunksize 5242880")); err == nil || !strings.Contains(err.Error(), "multipartChunkSize") {
+		t.Errorf("Should reject an unknown key with a suggestion. %v", err)
+	}
+	if err := c.Run(parseArgs("set storage.retry two")); err == nil {
+		t.Error("Should reject an invalid integer.")
+	}
+	b, _ := ioutil.ReadFile(filename)
+	expected := "# dagtools\n[storage]\nendpoint = storage-dag.iijgio.com\nretry = 5 # number of retries\n"
+	if string(b) != expected {
+		t.Errorf("Should keep the comments and the order. %q", string(b))
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	c, filename := newConfigCommand(t, `[dagtools]
+concurrency = four
+
+[storage]
+endpoint = storage-dag.iijgio.com
+multipartChunksize = 5242880
+secure = yes
+abortOnFailure = TRUE
+
+[profile staging]
+signatureVersion = 4
+insecureSkipVerify = f
+
+[unknown]
+foo = bar
+`)
+	defer os.RemoveAll(filepath.Dir(filename))
+	err := c.Run(parseArgs("validate -offline"))
+	if err == nil || !strings.HasPrefix(err.Error(), "4 problem(s)") {
+		t.Errorf("Should report the problems. %v", err)
+	}
+}
+
+func TestConfigValidateEndpoint(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	c, filename := newConfigCommand(t, "[storage]\nendpoint = "+addr+"\nsecure = false\n")
+	defer os.RemoveAll(filepath.Dir(filename))
+	if err := c.Run(parseArgs("validate")); err != nil {
+		t.Error("unknown error:", err)
+	}
+	l.Close()
+	if err := c.Run(parseArgs("validate")); err == nil {
+		t.Error("Should report the unreachable endpoint.")
+	}
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/iij/dagtools/client"
+	"github.com/iij/dagtools/env"
+)
+
+var (
+	corsSubCommands = map[string]bool{
+		"put": true,
+		"rm":  true,
+		"cat": true,
+	}
+)
+
+type corsCommand struct {
+	env    *env.Environment
+	cli    client.StorageClient
+	opts   *flag.FlagSet
+	asJSON bool
+}
+
+func (c *corsCommand) Description() string {
+	return "manage a bucket CORS configuration (put, cat, rm)"
+}
+
+func (c *corsCommand) Usage() string {
+	return fmt.Sprintf(`Command Usage:
+  cors cat [-json] <bucket>
+  cors rm <bucket>
+  cors put <bucket> <file>
+  cors put <bucket> < <file>
+
+The configuration file is written in XML (CORSConfiguration) or JSON:
+  {"CORSRules": [{"AllowedOrigins": ["https://example.com"], "AllowedMethods": ["GET", "PUT"],
+                  "AllowedHeaders": ["*"], "ExposeHeaders": ["ETag"], "MaxAgeSeconds": 3000}]}
+
+Options:
+%s`, OptionUsage(c.opts))
+}
+
+func (c *corsCommand) Init(env *env.Environment) (err error) {
+	c.env = env
+	c.cli, _ = client.NewStorageClient(env)
+	opts := flag.NewFlagSet("cors", flag.ExitOnError)
+	opts.BoolVar(&c.asJSON, "json", false, "print the configuration in JSON")
+	opts.Usage = func() {
+		fmt.Fprintln(os.Stdout, c.Usage())
+	}
+	c.opts = opts
+	return
+}
+
+func (c *corsCommand) Run(args []string) (err error) {
+	if len(args) < 1 {
+		return ErrArgument
+	}
+	command := args[0]
+	if !corsSubCommands[command] {
+		return fmt.Errorf("cors's sub-command not found: %q", command)
+	}
+	c.opts.Parse(args[1:])
+	argv := c.opts.Args()
+	if len(argv) < 1 || argv[0] == "" {
+		return ErrArgument
+	}
+	bucket := argv[0]
+	switch command {
+	case "put":
+		cors, err := c.readConfiguration(argv[1:])
+		if err != nil {
+			return err
+		}
+		return c.cli.PutBucketCors(bucket, cors)
+	case "cat":
+		if len(argv) != 1 {
+			return ErrArgument
+		}
+		cors, err := c.cli.GetBucketCors(bucket)
+		if err != nil {
+			return err
+		}
+		var b []byte
+		if c.asJSON {
+			b, err = json.MarshalIndent(cors, "", "  ")
+		} else {
+			b, err = xml.MarshalIndent(cors, "", "  ")
+			b = append([]byte(xml.Header), b...)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	case "rm":
+		if len(argv) != 1 {
+			return ErrArgument
+		}
+		return c.cli.DeleteBucketCors(bucket)
+	}
+	return
+}
+
+// readConfiguration reads a CORS configuration in XML or JSON from the file or stdin, and validates it.
+func (c *corsCommand) readConfiguration(argv []string) (cors *client.CORSConfiguration, err error) {
+	in, err := openInput(argv)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	b, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	cors, err = parseCORSConfiguration(b)
+	if err != nil {
+		return nil, err
+	}
+	if err = cors.Validate(); err != nil {
+		return nil, err
+	}
+	return cors, nil
+}
+
+// parseCORSConfiguration parses a CORS configuration. The format (JSON or XML) is detected from the first character.
+func parseCORSConfiguration(b []byte) (cors *client.CORSConfiguration, err error) {
+	cors = new(client.CORSConfiguration)
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+		err = json.Unmarshal(b, cors)
+	} else {
+		err = xml.Unmarshal(b, cors)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid cors configuration: %v", err)
+	}
+	return cors, nil
+}
+
+func init() {
+	Commands.Register(new(corsCommand), "cors")
+}
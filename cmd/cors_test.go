@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/iij/dagtools/client"
+	"github.com/iij/dagtools/env"
+	"github.com/iij/dagtools/ini"
+	"github.com/golang/mock/gomock"
+)
+
+func TestCorsUsage(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(corsCommand)
+	c.Init(&e)
+	usage := c.Usage()
+	if !strings.HasPrefix(usage, "Command Usage:") {
+		t.Errorf("Failed to get a cors command usage. usage: %q", usage)
+	}
+}
+
+func TestPutCorsWithJSON(t *testing.T) {
+	fd, err := ioutil.TempFile("", "cors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(fd.Name())
+	fd.WriteString(`{"CORSRules": [{"AllowedOrigins": ["https://example.com"], "AllowedMethods": ["GET", "PUT"], "MaxAgeSeconds": 3000}]}`)
+	fd.Close()
+
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(corsCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	mock.EXPECT().PutBucketCors("mybucket", gomock.Any()).Do(func(bucket string, cors *client.CORSConfiguration) {
+		if len(cors.Rules) != 1 || len(cors.Rules[0].AllowedMethods) != 2 || cors.Rules[0].MaxAgeSeconds != 3000 {
+			t.Errorf("Should pass the configuration read from the file. %v", cors.Rules)
+		}
+	}).Return(nil)
+	c.cli = mock
+	if err := c.Run(parseArgs("put mybucket " + fd.Name())); err != nil {
+		t.Error("unknown error:", err)
+	}
+}
+
+func TestPutCorsInvalidRule(t *testing.T) {
+	fd, err := ioutil.TempFile("", "cors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(fd.Name())
+	fd.WriteString(`<CORSConfiguration><CORSRule><AllowedOrigin>*</AllowedOrigin><AllowedMethod>PATCH</AllowedMethod></CORSRule></CORSConfiguration>`)
+	fd.Close()
+
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(corsCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	c.cli = client.NewMockStorageClient(ctrl)
+	err = c.Run(parseArgs("put mybucket " + fd.Name()))
+	if err == nil || err.Error() != `invalid cors configuration: rule #1: invalid AllowedMethod: "PATCH"` {
+		t.Error("unknown error:", err)
+	}
+}
+
+func TestCatCors(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(corsCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	mock.EXPECT().GetBucketCors("mybucket").Return(nil, errors.New("ok"))
+	c.cli = mock
+	err := c.Run(parseArgs("cat -json mybucket"))
+	if err == nil || err.Error() != "ok" {
+		t.Error("unknown error:", err)
+	}
+}
+
+func TestRmCors(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(corsCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	mock.EXPECT().DeleteBucketCors("mybucket").Return(errors.New("ok"))
+	c.cli = mock
+	err := c.Run(parseArgs("rm mybucket"))
+	if err == nil || err.Error() != "ok" {
+		t.Error("unknown error:", err)
+	}
+}
+
+func TestCorsUnknownCommand(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(corsCommand)
+	c.Init(&e)
+	err := c.Run(parseArgs("unknown mybucket"))
+	if err == nil || err.Error() != `cors's sub-command not found: "unknown"` {
+		t.Error("unknown error:", err)
+	}
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/iij/dagtools/client"
+	"github.com/iij/dagtools/env"
+)
+
+type cpCommand struct {
+	env       *env.Environment
+	cli       client.StorageClient
+	opts      *flag.FlagSet
+	recursive bool
+	limitRate string
+}
+
+func (c *cpCommand) Description() string {
+	return "copy object[s] on DAG storage or between local files and DAG storage"
+}
+
+func (c *cpCommand) Usage() string {
+	return fmt.Sprintf(`Command Usage:
+  cp <bucket>:<key> <bucket>:<key>
+  cp <bucket>:<key> <bucket>:<prefix>/
+  cp -r <bucket>:<prefix>/ <bucket>:<prefix>/
+  cp <file> <bucket>:<key>
+  cp -r <dir> <bucket>:<prefix>/
+  cp <bucket>:<key> <file>
+  cp -r <bucket>:<prefix>/ <dir>
+
+Options:
+%s`, OptionUsage(c.opts))
+}
+
+func (c *cpCommand) Init(env *env.Environment) (err error) {
+	c.env = env
+	c.cli, _ = client.NewStorageClient(env)
+	opts := flag.NewFlagSet("cp", flag.ExitOnError)
+	opts.BoolVar(&c.recursive, "r", false, "recursively copy")
+	opts.StringVar(&c.limitRate, "limit-rate", "", "limit the transfer rate in bytes per second (e.g., 512K, 20M)")
+	opts.Usage = func() {
+		fmt.Fprintln(os.Stdout, c.Usage())
+	}
+	c.opts = opts
+	return
+}
+
+func (c *cpCommand) Run(args []string) (err error) {
+	c.opts.Parse(args)
+	argv := c.opts.Args()
+	if len(argv) != 2 {
+		return ErrArgument
+	}
+	if c.cli, err = limitRate(c.cli, c.limitRate); err != nil {
+		return err
+	}
+	srcBucket, srcKey, srcRemote := splitResource(argv[0])
+	dstBucket, dstKey, dstRemote := splitResource(argv[1])
+	if (srcRemote && srcBucket == "") || (dstRemote && dstBucket == "") {
+		return ErrArgument
+	}
+	if (srcRemote && strings.HasPrefix(srcKey, "/")) || (dstRemote && strings.HasPrefix(dstKey, "/")) {
+		return errors.New("object key must not include the slash(/) at the beginning of the value")
+	}
+	switch {
+	case srcRemote && dstRemote:
+		if c.recursive {
+			return c.copyObjects(srcBucket, srcKey, dstBucket, dstKey)
+		}
+		return c.copyObject(srcBucket, srcKey, dstBucket, dstKey)
+	case !srcRemote && dstRemote:
+		return c.upload(srcKey, dstBucket, dstKey)
+	case srcRemote && !dstRemote:
+		if c.recursive {
+			return c.downloadObjects(srcBucket, srcKey, dstKey)
+		}
+		return c.download(srcBucket, srcKey, dstKey)
+	}
+	return errors.New("either source or destination must be an object on DAG storage")
+}
+
+func (c *cpCommand) copyObject(srcBucket, srcKey, dstBucket, dstKey string) (err error) {
+	if srcKey == "" || strings.HasSuffix(srcKey, "/") {
+		return fmt.Errorf("%s:%s is a directory (use -r option)", srcBucket, srcKey)
+	}
+	if dstKey == "" || strings.HasSuffix(dstKey, "/") {
+		dstKey += path.Base(srcKey)
+	}
+	if _, err = c.cli.CopyObject(srcBucket, srcKey, dstBucket, dstKey, nil); err != nil {
+		return err
+	}
+	if c.env.Verbose {
+		fmt.Printf("copy: %s:%s -> %s:%s\n", srcBucket, srcKey, dstBucket, dstKey)
+	}
+	return
+}
+
+func (c *cpCommand) copyObjects(srcBucket, srcPrefix, dstBucket, dstPrefix string) (err error) {
+	if dstPrefix != "" && !strings.HasSuffix(dstPrefix, "/") {
+		dstPrefix += "/"
+	}
+	failed := 0
+	err = walkObjects(c.cli, srcBucket, srcPrefix, func(o client.ObjectSummary, name string) error {
+		dstKey := dstPrefix + name
+		if name == "" {
+			dstKey = dstPrefix + path.Base(o.Key)
+		}
+		if _, err := c.cli.CopyObject(srcBucket, o.Key, dstBucket, dstKey, nil); err != nil {
+			c.env.EventLogger.Log(env.LevelError, fmt.Sprintf("Failed to copy %s:%s to %s:%s. %s", srcBucket, o.Key, dstBucket, dstKey, err))
+			fmt.Fprintf(os.Stderr, "[Error] %s:%s: %v\n", srcBucket, o.Key, err)
+			failed++
+			return nil
+		}
+		if c.env.Verbose {
+			fmt.Printf("copy: %s:%s -> %s:%s\n", srcBucket, o.Key, dstBucket, dstKey)
+		}
+		return nil
+	})
+	if err == nil && failed > 0 {
+		err = fmt.Errorf("failed to copy %d object(s)", failed)
+	}
+	return
+}
+
+func (c *cpCommand) upload(root, bucket, prefix string) (err error) {
+	fi, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		key := prefix
+		if key == "" || strings.HasSuffix(key, "/") {
+			key += filepath.Base(root)
+		}
+		return c.uploadFile(root, bucket, key)
+	}
+	if !c.recursive {
+		return fmt.Errorf("%q is a directory (use -r option)", root)
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		return c.uploadFile(p, bucket, prefix+filepath.ToSlash(rel))
+	})
+}
+
+func (c *cpCommand) uploadFile(filename, bucket, key string) (err error) {
+	fd, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+	if err = c.cli.UploadFile(bucket, key, fd, nil); err != nil {
+		return err
+	}
+	if c.env.Verbose {
+		fmt.Printf("copy: %s -> %s:%s\n", filename, bucket, key)
+	}
+	return
+}
+
+func (c *cpCommand) download(bucket, key, target string) (err error) {
+	if key == "" || strings.HasSuffix(key, "/") {
+		return fmt.Errorf("%s:%s is a directory (use -r option)", bucket, key)
+	}
+	if fi, err := os.Stat(target); err == nil && fi.IsDir() {
+		target = filepath.Join(target, path.Base(key))
+	}
+	return c.downloadFile(bucket, key, target)
+}
+
+func (c *cpCommand) downloadObjects(bucket, prefix, dir string) (err error) {
+	failed := 0
+	err = walkObjects(c.cli, bucket, prefix, func(o client.ObjectSummary, name string) error {
+		if strings.HasSuffix(o.Key, "/") {
+			return nil
+		}
+		if name == "" {
+			name = path.Base(o.Key)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := c.downloadFile(bucket, o.Key, target); err != nil {
+			c.env.EventLogger.Log(env.LevelError, fmt.Sprintf("Failed to copy %s:%s to %s. %s", bucket, o.Key, target, err))
+			fmt.Fprintf(os.Stderr, "[Error] %s:%s: %v\n", bucket, o.Key, err)
+			failed++
+		}
+		return nil
+	})
+	if err == nil && failed > 0 {
+		err = fmt.Errorf("failed to copy %d object(s)", failed)
+	}
+	return
+}
+
+func (c *cpCommand) downloadFile(bucket, key, target string) (err error) {
+	if err = downloadFile(c.env, c.cli, bucket, key, target, false); err != nil {
+		return err
+	}
+	if c.env.Verbose {
+		fmt.Printf("copy: %s:%s -> %s\n", bucket, key, target)
+	}
+	return
+}
+
+func init() {
+	Commands.Register(new(cpCommand), "cp")
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/iij/dagtools/client"
+	"github.com/iij/dagtools/env"
+	"github.com/iij/dagtools/ini"
+	"github.com/golang/mock/gomock"
+)
+
+func TestCpUsage(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(cpCommand)
+	c.Init(&e)
+	usage := c.Usage()
+	if !strings.HasPrefix(usage, "Command Usage:") {
+		t.Errorf("Failed to get a cp command usage. usage: %q", usage)
+	}
+}
+
+func TestCpAnObject(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(cpCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	mock.EXPECT().CopyObject("mybucket", "foo/bar", "mybucket2", "baz", nil).Return(nil, errors.New("dummy"))
+	mock.EXPECT().CopyObject("mybucket", "foo/bar", "mybucket2", "baz/bar", nil).Return(&client.CopyObjectResult{}, nil)
+	c.cli = mock
+	err := c.Run(parseArgs("mybucket:foo/bar mybucket2:baz"))
+	if err == nil || err.Error() != "dummy" {
+		t.Errorf("Error message was not match. dummy != %v", err)
+	}
+	err = c.Run(parseArgs("mybucket:foo/bar mybucket2:baz/"))
+	if err != nil {
+		t.Error("Failed to copy an object.", err)
+	}
+}
+
+func TestCpObjectsRecursively(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(cpCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	listing := &client.ObjectListing{
+		Name:   "mybucket",
+		Prefix: "foo",
+		Summaries: []client.ObjectSummary{
+			{Key: "foo/a"},
+			{Key: "foo/b/c"},
+			{Key: "foobar"},
+		},
+	}
+	mock.EXPECT().ListObjects("mybucket", "foo", "", "", 1000).Return(listing, nil)
+	mock.EXPECT().CopyObject("mybucket", "foo/a", "mybucket2", "bar/a", nil).Return(&client.CopyObjectResult{}, nil)
+	mock.EXPECT().CopyObject("mybucket", "foo/b/c", "mybucket2", "bar/b/c", nil).Return(nil, errors.New("dummy"))
+	c.cli = mock
+	err := c.Run(parseArgs("-r mybucket:foo mybucket2:bar"))
+	if err == nil || err.Error() != "failed to copy 1 object(s)" {
+		t.Errorf("Error message was not match. failed to copy 1 object(s) != %v", err)
+	}
+}
+
+func TestCpBucketRecursively(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(cpCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	listing := &client.ObjectListing{
+		Name:      "mybucket",
+		Summaries: []client.ObjectSummary{{Key: "a"}, {Key: "b/c"}},
+	}
+	mock.EXPECT().ListObjects("mybucket", "", "", "", 1000).Return(listing, nil)
+	mock.EXPECT().CopyObject("mybucket", "a", "mybucket2", "a", nil).Return(&client.CopyObjectResult{}, nil)
+	mock.EXPECT().CopyObject("mybucket", "b/c", "mybucket2", "b/c", nil).Return(&client.CopyObjectResult{}, nil)
+	c.cli = mock
+	if err := c.Run(parseArgs("-r mybucket: mybucket2:")); err != nil {
+		t.Error("unknown error:", err)
+	}
+}
+
+func TestCpAFileToDag(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(cpCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	mock.EXPECT().UploadFile("mybucket", "foo/test-00.txt", fileMatcher{"test_files/test-00.txt"}, nil).Return(errors.New("dummy"))
+	c.cli = mock
+	err := c.Run(parseArgs("test_files/test-00.txt mybucket:foo/"))
+	if err == nil || err.Error() != "dummy" {
+		t.Errorf("Error message was not match. dummy != %v", err)
+	}
+}
+
+func TestCpAnObjectToLocal(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(cpCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	mock.EXPECT().DownloadFile("mybucket", "foo/bar", gomock.Any()).Return(errors.New("dummy"))
+	c.cli = mock
+	err := c.Run(parseArgs("mybucket:foo/bar test_files/"))
+	if err == nil || err.Error() != "dummy" {
+		t.Errorf("Error message was not match. dummy != %v", err)
+	}
+	if _, err := os.Stat("test_files/bar"); !os.IsNotExist(err) {
+		t.Error("Should remove the file created by the failed download.", err)
+	}
+}
+
+func TestCpIllegalArgument(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(cpCommand)
+	c.Init(&e)
+	if err := c.Run(parseArgs("mybucket:foo")); err != ErrArgument {
+		t.Errorf("%v != %v", ErrArgument, err)
+	}
+	if err := c.Run(parseArgs("foo bar")); err == nil {
+		t.Error("Failed to get an error.")
+	}
+	if err := c.Run(parseArgs("mybucket:foo mybucket:/bar")); err == nil {
+		t.Error("Failed to get an error.")
+	}
+}
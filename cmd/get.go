@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -17,6 +18,10 @@ type getCommand struct {
 	cli       client.StorageClient
 	opts      *flag.FlagSet
 	recursive bool
+	resume    bool
+	byteRange string
+	limitRate string
+	progress  bool
 }
 
 func (c *getCommand) Description() string {
@@ -27,9 +32,12 @@ func (c *getCommand) Usage() string {
 	return fmt.Sprintf(`Command Usage:
   get <bucket>:<key>
   get <bucket>:<key> <file>
+  get -range=<first>-<last> <bucket>:<key> <file>
   get -r <bucket>:<prefix>
   get -r <bucket>:<prefix> <dir>/
   get -r <bucket>:<prefix> <dir>/<dirname>
+  get -c <bucket>:<key> <file>
+  get -c -r <bucket>:<prefix> <dir>/
 
 Options:
 %s`, OptionUsage(c.opts))
@@ -40,6 +48,10 @@ func (c *getCommand) Init(env *env.Environment) (err error) {
 	c.cli, _ = client.NewStorageClient(env)
 	opts := flag.NewFlagSet("get", flag.ExitOnError)
 	opts.BoolVar(&c.recursive, "r", false, "recursively download")
+	opts.BoolVar(&c.resume, "c", false, "continue getting partially-downloaded file[s] and skip already downloaded file[s]")
+	opts.StringVar(&c.byteRange, "range", "", "download only the specified byte range (e.g., 0-1023, 1024-, -1024)")
+	opts.StringVar(&c.limitRate, "limit-rate", "", "limit the transfer rate in bytes per second (e.g., 512K, 20M)")
+	opts.BoolVar(&c.progress, "progress", false, "show the progress of the transfers to stderr")
 	opts.Usage = func() {
 		fmt.Fprintln(os.Stdout, c.Usage())
 	}
@@ -56,11 +68,25 @@ func (c *getCommand) Run(args []string) (err error) {
 	// parse the sub-command line arguments
 	c.opts.Parse(args)
 	argv := c.opts.Args()
+	if c.cli, err = limitRate(c.cli, c.limitRate); err != nil {
+		return err
+	}
+	if c.progress {
+		var stop func()
+		c.cli, stop = showProgress(c.cli)
+		defer stop()
+	}
 
 	// check specified args
 	if len(argv) < 1 || 2 < len(argv) {
 		return ErrArgument
 	}
+	if c.recursive && c.byteRange != "" {
+		return errors.New("-range option cannot be used with -r option")
+	}
+	if c.resume && c.byteRange != "" {
+		return errors.New("-range option cannot be used with -c option")
+	}
 	slice := strings.Split(argv[0], ":")
 	if len(slice) < 2 {
 		return ErrArgument
@@ -98,12 +124,23 @@ func (c *getCommand) getObject(bucket string, key string, target string) (err er
 		_names := strings.Split(key, "/")
 		target = _names[len(_names)-1]
 	}
-	in, err := c.cli.GetObject(bucket, key)
+	if c.byteRange != "" {
+		return c.getObjectRange(bucket, key, target)
+	}
+	return c.downloadFile(bucket, key, target)
+}
+
+func (c *getCommand) getObjectRange(bucket, key, target string) (err error) {
+	off, length, err := parseRange(c.byteRange)
+	if err != nil {
+		return err
+	}
+	in, _, err := c.cli.GetObjectRange(bucket, key, off, length)
 	if err != nil {
 		return err
 	}
 	defer in.Close()
-	out, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, 0644)
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
 	if err != nil {
 		return err
 	}
@@ -142,6 +179,9 @@ func (c *getCommand) getObjectRecursively(bucket string, prefix string, dir stri
 			break
 		}
 		for _, o := range listing.Summaries {
+			if interrupted(c.env) {
+				return c.env.Context.Err()
+			}
 			if prefix != o.Key && !strings.HasSuffix(prefix, "/") && !strings.HasPrefix(o.Key, prefix+"/") {
 				continue
 			}
@@ -169,32 +209,25 @@ func (c *getCommand) getObjectRecursively(bucket string, prefix string, dir stri
 }
 
 func (c *getCommand) writeFile(bucket string, o client.ObjectSummary, target string) (err error) {
-	r, err := c.cli.GetObject(bucket, o.Key)
-	if err != nil {
-		c.env.Logger.Printf("Failed to get object: %s/%s. %s", bucket, o.Key, err)
-		fmt.Fprintf(os.Stderr, "[Error] %v\n", err)
-		return
-	}
-	defer r.Close()
-	file, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, 0644)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "[Error] %v\n", err)
-		return
-	}
-	defer file.Close()
-	br := bufio.NewReader(r)
-	bw := bufio.NewWriter(file)
-	defer bw.Flush()
 	if c.env.Verbose {
 		fmt.Printf("get: %s:%s -> %s\n", bucket, o.Key, target)
 	}
-	if _, err := br.WriteTo(bw); err != nil {
+	if err := c.downloadFile(bucket, o.Key, target); err != nil {
+		c.env.EventLogger.Log(env.LevelError, fmt.Sprintf("Failed to get object: %s/%s. %s", bucket, o.Key, err))
 		fmt.Fprintf(os.Stderr, "[Error] %v\n", err)
-		c.env.Logger.Printf("Failed to write file: %s. %s", target, err)
 	}
 	return nil
 }
 
+func (c *getCommand) downloadFile(bucket, key, target string) (err error) {
+	if err = downloadFile(c.env, c.cli, bucket, key, target, c.resume); err != nil {
+		if _, serr := os.Stat(client.DownloadStateFilename(target)); serr == nil {
+			fmt.Fprintf(os.Stderr, "%s was partially downloaded. Use -c option to resume.\n", target)
+		}
+	}
+	return
+}
+
 func init() {
 	Commands.Register(new(getCommand), "get")
 }
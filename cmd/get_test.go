@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"context"
 	"errors"
+	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
 
@@ -31,7 +34,7 @@ func TestGetAnObject(t *testing.T) {
 	c.Init(&e)
 	ctrl := gomock.NewController(t)
 	mock := client.NewMockStorageClient(ctrl)
-	mock.EXPECT().GetObject("mybucket", "foo/bar").Return(nil, errors.New("dummy"))
+	mock.EXPECT().DownloadFile("mybucket", "foo/bar", fileMatcher{"bar"}).Return(errors.New("dummy"))
 	c.cli = mock
 	err := c.Run(parseArgs("mybucket:foo/bar"))
 	if err == nil {
@@ -40,6 +43,9 @@ func TestGetAnObject(t *testing.T) {
 	if err.Error() != "dummy" {
 		t.Errorf("Error message was not match. dummy != %v", err.Error())
 	}
+	if _, err := os.Stat("bar"); !os.IsNotExist(err) {
+		t.Error("Should remove the file created by the failed download.", err)
+	}
 }
 
 func TestGetAnObjectWithIllegalArgument(t *testing.T) {
@@ -61,6 +67,72 @@ func TestGetAnObjectWithIllegalArgument(t *testing.T) {
 	}
 }
 
+func TestGetAnObjectWithRange(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(getCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	mock.EXPECT().GetObjectRange("mybucket", "foo/bar", int64(1024), int64(0)).Return(nil, nil, errors.New("dummy"))
+	c.cli = mock
+	err := c.Run(parseArgs("-range 1024- mybucket:foo/bar"))
+	if err == nil || err.Error() != "dummy" {
+		t.Errorf("Error message was not match. dummy != %v", err)
+	}
+	err = c.Run(parseArgs("-r -range 1024- mybucket:foo/"))
+	if err == nil {
+		t.Error("Failed to get an error.")
+	}
+}
+
+func TestGetAnObjectWithResume(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(getCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	mock.EXPECT().ResumeDownloadFile("mybucket", "foo/bar", fileMatcher{"bar"}).Return(errors.New("dummy"))
+	c.cli = mock
+	err := c.Run(parseArgs("-c mybucket:foo/bar"))
+	if err == nil || err.Error() != "dummy" {
+		t.Errorf("Error message was not match. dummy != %v", err)
+	}
+	err = c.Run(parseArgs("-c -range 0-1 mybucket:foo/bar"))
+	if err == nil {
+		t.Error("Failed to get an error.")
+	}
+}
+
+func TestGetAnObjectInterrupted(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	ctx, cancel := context.WithCancel(context.Background())
+	e := env.Environment{Config: config, Context: ctx}
+	e.Init()
+	c := new(getCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	mock.EXPECT().DownloadFile("mybucket", "foo/bar", fileMatcher{"bar"}).Do(func(bucket, key string, fd *os.File) {
+		ioutil.WriteFile(client.DownloadStateFilename(fd.Name()), []byte("{}"), 0644)
+		cancel()
+	}).Return(context.Canceled)
+	c.cli = mock
+	err := c.Run(parseArgs("mybucket:foo/bar"))
+	if err != context.Canceled {
+		t.Errorf("%v != %v", context.Canceled, err)
+	}
+	if _, err := os.Stat("bar"); !os.IsNotExist(err) {
+		t.Error("Should remove the partially downloaded file.", err)
+	}
+	if _, err := os.Stat(client.DownloadStateFilename("bar")); !os.IsNotExist(err) {
+		t.Error("Should remove the download state file.", err)
+	}
+}
+
 func TestGetObjectsRecursively(t *testing.T) {
 	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
 	e := env.Environment{Config: config}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"reflect"
@@ -148,11 +149,24 @@ func Run(e *env.Environment, cmdName string, cmdArgs []string) int {
 		fmt.Fprintf(os.Stderr, "[Error] command not found: %q \n", cmdName)
 		return 1
 	}
+	if e.Context == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		e.Context = ctx
+		defer notifyInterrupt(e, cancel)()
+	}
+	defer notifyHangup(e)()
 	_cmd, _ := Commands.Lookup(cmdName)
 	_cmd.Init(e)
 	e.Logger.Printf("Starting %q command ..., args: %s", cmdName, cmdArgs)
 	err := _cmd.Run(cmdArgs)
+	defer reportStats(e, cmdName)
 	if err != nil {
+		if interrupted(e) {
+			e.EventLogger.Log(env.LevelWarn, fmt.Sprintf("%q command interrupted. %v", cmdName, err))
+			fmt.Fprintln(os.Stderr, "[Error] interrupted")
+			return 130
+		}
 		if err == ErrArgument {
 			fmt.Fprintf(os.Stderr, "[Error] illegal argument: %v\n", cmdArgs)
 			fmt.Fprintln(os.Stderr, _cmd.Usage())
@@ -164,3 +178,17 @@ func Run(e *env.Environment, cmdName string, cmdArgs []string) int {
 	e.Logger.Printf("%q command finished. (elapsed time: %d ms)", cmdName, e.GetElapsedTimeMs())
 	return 0
 }
+
+// reportStats records the transfer statistics of the command to the log, and prints them if -stats is specified.
+func reportStats(e *env.Environment, cmdName string) {
+	s := e.Stats.Summary()
+	e.EventLogger.Log(env.LevelInfo, "stats", "command", cmdName, "retries", s.Retries,
+		"bytesSent", s.BytesSent, "bytesReceived", s.BytesReceived,
+		"objectsTransferred", s.ObjectsTransferred, "objectsSkipped", s.ObjectsSkipped, "elapsedMs", s.ElapsedMs)
+	if e.StatsFormat == "" {
+		return
+	}
+	if err := PrintStats(os.Stderr, s, e.StatsFormat); err != nil {
+		fmt.Fprintln(os.Stderr, "[Error]", err)
+	}
+}
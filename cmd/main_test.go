@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bytes"
 	"strings"
 	"testing"
 
@@ -89,3 +90,29 @@ func TestRunSubCommand(t *testing.T) {
 		t.Errorf("Invalid command arguments. %v", _dummyCmd.args)
 	}
 }
+
+func TestPrintStats(t *testing.T) {
+	s := env.NewStats()
+	s.AddRequest("PUT", 200)
+	s.AddRequest("GET", 0)
+	s.AddSent(2048)
+	var out bytes.Buffer
+	if err := PrintStats(&out, s.Summary(), "table"); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"GET     -       1", "PUT     200     1", "Bytes sent:           2048"} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("The table should contain %q: %q", line, out.String())
+		}
+	}
+	out.Reset()
+	if err := PrintStats(&out, s.Summary(), "json"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"bytesSent":2048`) {
+		t.Errorf("Unexpected JSON: %q", out.String())
+	}
+	if err := PrintStats(&out, s.Summary(), "xml"); err == nil {
+		t.Error("Should fail with an unknown format.")
+	}
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/iij/dagtools/client"
+	"github.com/iij/dagtools/env"
+)
+
+type mvCommand struct {
+	env       *env.Environment
+	cli       client.StorageClient
+	opts      *flag.FlagSet
+	recursive bool
+	failed    int
+}
+
+func (c *mvCommand) Description() string {
+	return "move (rename) object[s] on DAG storage"
+}
+
+func (c *mvCommand) Usage() string {
+	return fmt.Sprintf(`Command Usage:
+  mv <bucket>:<key> <bucket>:<key>
+  mv <bucket>:<key> <bucket>:<prefix>/
+  mv -r <bucket>:<prefix>/ <bucket>:<prefix>/
+
+Options:
+%s`, OptionUsage(c.opts))
+}
+
+func (c *mvCommand) Init(env *env.Environment) (err error) {
+	c.env = env
+	c.cli, _ = client.NewStorageClient(env)
+	opts := flag.NewFlagSet("mv", flag.ExitOnError)
+	opts.BoolVar(&c.recursive, "r", false, "recursively move")
+	opts.Usage = func() {
+		fmt.Fprintln(os.Stdout, c.Usage())
+	}
+	c.opts = opts
+	return
+}
+
+func (c *mvCommand) Run(args []string) (err error) {
+	c.opts.Parse(args)
+	argv := c.opts.Args()
+	if len(argv) != 2 {
+		return ErrArgument
+	}
+	srcBucket, srcKey, srcRemote := splitResource(argv[0])
+	dstBucket, dstKey, dstRemote := splitResource(argv[1])
+	if !srcRemote || !dstRemote || srcBucket == "" || dstBucket == "" {
+		return ErrArgument
+	}
+	if strings.HasPrefix(srcKey, "/") || strings.HasPrefix(dstKey, "/") {
+		return errors.New("object key must not include the slash(/) at the beginning of the value")
+	}
+	if c.recursive {
+		return c.moveObjects(srcBucket, srcKey, dstBucket, dstKey)
+	}
+	return c.moveObject(srcBucket, srcKey, dstBucket, dstKey)
+}
+
+func (c *mvCommand) moveObject(srcBucket, srcKey, dstBucket, dstKey string) (err error) {
+	if srcKey == "" || strings.HasSuffix(srcKey, "/") {
+		return fmt.Errorf("%s:%s is a directory (use -r option)", srcBucket, srcKey)
+	}
+	if dstKey == "" || strings.HasSuffix(dstKey, "/") {
+		dstKey += path.Base(srcKey)
+	}
+	if srcBucket == dstBucket && srcKey == dstKey {
+		return fmt.Errorf("%s:%s and %s:%s are the same object", srcBucket, srcKey, dstBucket, dstKey)
+	}
+	if _, err = c.cli.CopyObject(srcBucket, srcKey, dstBucket, dstKey, nil); err != nil {
+		return err
+	}
+	if err = c.cli.DeleteObject(srcBucket, srcKey); err != nil {
+		return fmt.Errorf("copied to %s:%s but failed to delete %s:%s. %v", dstBucket, dstKey, srcBucket, srcKey, err)
+	}
+	if c.env.Verbose {
+		fmt.Printf("move: %s:%s -> %s:%s\n", srcBucket, srcKey, dstBucket, dstKey)
+	}
+	return
+}
+
+func (c *mvCommand) moveObjects(srcBucket, srcPrefix, dstBucket, dstPrefix string) (err error) {
+	if dstPrefix != "" && !strings.HasSuffix(dstPrefix, "/") {
+		dstPrefix += "/"
+	}
+	// "old" matches "old" and "old/...", but not "older/..."
+	srcDir := srcPrefix
+	if srcDir != "" && !strings.HasSuffix(srcDir, "/") {
+		srcDir += "/"
+	}
+	if srcBucket == dstBucket && strings.HasPrefix(dstPrefix, srcDir) {
+		return fmt.Errorf("cannot move %s:%s to a subdirectory of itself, %s:%s", srcBucket, srcPrefix, dstBucket, dstPrefix)
+	}
+	c.failed = 0
+	var (
+		copied = make([]string, 0, 1000)
+		dests  = make(map[string]string)
+	)
+	err = walkObjects(c.cli, srcBucket, srcPrefix, func(o client.ObjectSummary, name string) error {
+		dstKey := dstPrefix + name
+		if name == "" {
+			dstKey = dstPrefix + path.Base(o.Key)
+		}
+		if _, err := c.cli.CopyObject(srcBucket, o.Key, dstBucket, dstKey, nil); err != nil {
+			c.reportError(srcBucket, o.Key, err)
+			return nil
+		}
+		copied = append(copied, o.Key)
+		dests[o.Key] = dstKey
+		if len(copied) == cap(copied) {
+			if err := c.deleteCopiedObjects(srcBucket, copied, dstBucket, dests); err != nil {
+				return err
+			}
+			copied = copied[:0]
+			dests = make(map[string]string)
+		}
+		return nil
+	})
+	if len(copied) > 0 {
+		if _err := c.deleteCopiedObjects(srcBucket, copied, dstBucket, dests); _err != nil && err == nil {
+			err = _err
+		}
+	}
+	if err == nil && c.failed > 0 {
+		err = fmt.Errorf("failed to move %d object(s)", c.failed)
+	}
+	return
+}
+
+// deleteCopiedObjects deletes the source objects whose copy has been completed.
+func (c *mvCommand) deleteCopiedObjects(bucket string, keys []string, dstBucket string, dests map[string]string) error {
+	res, err := c.cli.DeleteMultipleObjects(bucket, keys, false)
+	if err != nil {
+		return err
+	}
+	for _, o := range res.DeletedObjects {
+		if c.env.Verbose {
+			fmt.Printf("move: %s:%s -> %s:%s\n", bucket, o.Key, dstBucket, dests[o.Key])
+		}
+	}
+	for _, e := range res.Errors {
+		c.reportError(bucket, e.Key, fmt.Errorf("copied to %s:%s but failed to delete. %v %v", dstBucket, dests[e.Key], e.Code, e.Message))
+	}
+	return nil
+}
+
+func (c *mvCommand) reportError(bucket, key string, err error) {
+	c.failed++
+	c.env.EventLogger.Log(env.LevelError, fmt.Sprintf("Failed to move %s:%s. %s", bucket, key, err))
+	fmt.Fprintf(os.Stderr, "[Error] %s:%s: %v\n", bucket, key, err)
+}
+
+func init() {
+	Commands.Register(new(mvCommand), "mv")
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/iij/dagtools/client"
+	"github.com/iij/dagtools/env"
+	"github.com/iij/dagtools/ini"
+	"github.com/golang/mock/gomock"
+)
+
+func TestMvUsage(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(mvCommand)
+	c.Init(&e)
+	usage := c.Usage()
+	if !strings.HasPrefix(usage, "Command Usage:") {
+		t.Errorf("Failed to get a mv command usage. usage: %q", usage)
+	}
+}
+
+func TestMvAnObject(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(mvCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	gomock.InOrder(
+		mock.EXPECT().CopyObject("mybucket", "foo/bar", "mybucket", "baz/bar", nil).Return(&client.CopyObjectResult{}, nil),
+		mock.EXPECT().DeleteObject("mybucket", "foo/bar").Return(nil),
+	)
+	c.cli = mock
+	if err := c.Run(parseArgs("mybucket:foo/bar mybucket:baz/")); err != nil {
+		t.Error("Failed to move an object.", err)
+	}
+}
+
+func TestMvAnObjectCopyFailed(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(mvCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	mock.EXPECT().CopyObject("mybucket", "foo/bar", "mybucket", "baz", nil).Return(nil, errors.New("dummy"))
+	c.cli = mock
+	err := c.Run(parseArgs("mybucket:foo/bar mybucket:baz"))
+	if err == nil || err.Error() != "dummy" {
+		t.Errorf("Error message was not match. dummy != %v", err)
+	}
+}
+
+func TestMvObjectsRecursively(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(mvCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	listing := &client.ObjectListing{
+		Name:   "mybucket",
+		Prefix: "old/",
+		Summaries: []client.ObjectSummary{
+			{Key: "old/a"},
+			{Key: "old/b"},
+			{Key: "old/c/d"},
+		},
+	}
+	mock.EXPECT().ListObjects("mybucket", "old/", "", "", 1000).Return(listing, nil)
+	mock.EXPECT().CopyObject("mybucket", "old/a", "mybucket", "new/a", nil).Return(&client.CopyObjectResult{}, nil)
+	mock.EXPECT().CopyObject("mybucket", "old/b", "mybucket", "new/b", nil).Return(nil, errors.New("dummy"))
+	mock.EXPECT().CopyObject("mybucket", "old/c/d", "mybucket", "new/c/d", nil).Return(&client.CopyObjectResult{}, nil)
+	mock.EXPECT().DeleteMultipleObjects("mybucket", []string{"old/a", "old/c/d"}, false).Return(&client.MultipleDeletionResult{
+		DeletedObjects: []client.DeletedObject{{Key: "old/a"}},
+		Errors:         []client.MultipleDeletionError{{Key: "old/c/d", Code: "AccessDenied", Message: "Access Denied"}},
+	}, nil)
+	c.cli = mock
+	err := c.Run(parseArgs("-r mybucket:old/ mybucket:new/"))
+	if err == nil || err.Error() != "failed to move 2 object(s)" {
+		t.Errorf("Error message was not match. failed to move 2 object(s) != %v", err)
+	}
+}
+
+func TestMvIntoItself(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(mvCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	c.cli = client.NewMockStorageClient(ctrl)
+	if err := c.Run(parseArgs("-r mybucket:old/ mybucket:old/new/")); err == nil {
+		t.Error("Failed to get an error.")
+	}
+	if err := c.Run(parseArgs("-r mybucket:old mybucket:old")); err == nil {
+		t.Error("Failed to get an error.")
+	}
+	c.recursive = false
+	if err := c.Run(parseArgs("mybucket:foo mybucket:foo")); err == nil {
+		t.Error("Failed to get an error.")
+	}
+}
+
+func TestMvToSiblingPrefix(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(mvCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	listing := &client.ObjectListing{
+		Name:      "mybucket",
+		Prefix:    "old",
+		Summaries: []client.ObjectSummary{{Key: "old/a"}},
+	}
+	mock.EXPECT().ListObjects("mybucket", "old", "", "", 1000).Return(listing, nil)
+	mock.EXPECT().CopyObject("mybucket", "old/a", "mybucket", "older/a", nil).Return(&client.CopyObjectResult{}, nil)
+	mock.EXPECT().DeleteMultipleObjects("mybucket", []string{"old/a"}, false).Return(&client.MultipleDeletionResult{
+		DeletedObjects: []client.DeletedObject{{Key: "old/a"}},
+	}, nil)
+	c.cli = mock
+	if err := c.Run(parseArgs("-r mybucket:old mybucket:older/")); err != nil {
+		t.Error("unknown error:", err)
+	}
+}
+
+func TestMvIllegalArgument(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(mvCommand)
+	c.Init(&e)
+	if err := c.Run(parseArgs("mybucket:foo")); err != ErrArgument {
+		t.Errorf("%v != %v", ErrArgument, err)
+	}
+	if err := c.Run(parseArgs("mybucket:foo bar")); err != ErrArgument {
+		t.Errorf("%v != %v", ErrArgument, err)
+	}
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iij/dagtools/client"
+	"github.com/iij/dagtools/env"
+)
+
+type postPolicyCommand struct {
+	env                 *env.Environment
+	cli                 client.StorageClient
+	opts                *flag.FlagSet
+	expires             time.Duration
+	minSize             int64
+	maxSize             int64
+	contentType         string
+	contentTypePrefix   string
+	successActionStatus string
+	redirect            string
+	format              string
+}
+
+func (c *postPolicyCommand) Description() string {
+	return "generate a signed policy and form fields for browser-based uploads"
+}
+
+func (c *postPolicyCommand) Usage() string {
+	return fmt.Sprintf(`Command Usage:
+  post-policy [-expires=<duration>] [-min-size=<bytes>] [-max-size=<bytes>]
+              [-content-type=<type> | -content-type-prefix=<prefix>]
+              [-success-status=<status> | -redirect=<url>] [-format=json|html] <bucket>[:<key prefix>]
+
+If -content-type-prefix is specified, the form must have a "Content-Type" field which starts with the prefix.
+
+Options:
+%s`, OptionUsage(c.opts))
+}
+
+func (c *postPolicyCommand) Init(env *env.Environment) (err error) {
+	c.env = env
+	c.cli, _ = client.NewStorageClient(env)
+	opts := flag.NewFlagSet("post-policy", flag.ExitOnError)
+	opts.DurationVar(&c.expires, "expires", time.Hour, "expiration time of the policy (e.g. 30m, 1h, 168h)")
+	opts.Int64Var(&c.minSize, "min-size", 0, "minimum size of an uploaded file in bytes")
+	opts.Int64Var(&c.maxSize, "max-size", 0, "maximum size of an uploaded file in bytes (0: unlimited)")
+	opts.StringVar(&c.contentType, "content-type", "", "Content-Type of an uploaded file")
+	opts.StringVar(&c.contentTypePrefix, "content-type-prefix", "", "prefix of Content-Type of an uploaded file (e.g. image/)")
+	opts.StringVar(&c.successActionStatus, "success-status", "", "HTTP status of a successful upload (200, 201 or 204)")
+	opts.StringVar(&c.redirect, "redirect", "", "URL to redirect to after a successful upload")
+	opts.StringVar(&c.format, "format", "json", "output format (json, html)")
+	opts.Usage = func() {
+		fmt.Fprintln(os.Stdout, c.Usage())
+	}
+	c.opts = opts
+	return
+}
+
+func (c *postPolicyCommand) Run(args []string) (err error) {
+	c.opts.Parse(args)
+	argv := c.opts.Args()
+	if len(argv) != 1 {
+		return ErrArgument
+	}
+	bucket, prefix := argv[0], ""
+	if strings.Contains(bucket, ":") {
+		bucket, prefix, _ = splitResource(bucket)
+	}
+	if bucket == "" {
+		return ErrArgument
+	}
+	if strings.HasPrefix(prefix, "/") {
+		return errors.New("object key must not include the slash(/) at the beginning of the value")
+	}
+	if c.format != "json" && c.format != "html" {
+		return fmt.Errorf("unsupported format: %q", c.format)
+	}
+	policy := &client.PostPolicy{
+		Bucket:                bucket,
+		KeyPrefix:             prefix,
+		MinContentLength:      c.minSize,
+		MaxContentLength:      c.maxSize,
+		ContentType:           c.contentType,
+		ContentTypePrefix:     c.contentTypePrefix,
+		SuccessActionStatus:   c.successActionStatus,
+		SuccessActionRedirect: c.redirect,
+		Expiration:            time.Now().Add(c.expires),
+	}
+	form, err := c.cli.PresignPostPolicy(policy)
+	if err != nil {
+		return err
+	}
+	if c.format == "html" {
+		fmt.Print(formHTML(form, c.contentTypePrefix))
+		return
+	}
+	b, err := json.MarshalIndent(form, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return
+}
+
+// formHTML returns an HTML form for the upload. The fields are sorted by name, and the file field is the last.
+func formHTML(form *client.PostForm, contentTypePrefix string) string {
+	var names []string
+	for name := range form.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	fmt.Fprintf(&b, "<form action=\"%s\" method=\"post\" enctype=\"multipart/form-data\">\n", html.EscapeString(form.URL))
+	for _, name := range names {
+		fmt.Fprintf(&b, "  <input type=\"hidden\" name=\"%s\" value=\"%s\">\n", html.EscapeString(name), html.EscapeString(form.Fields[name]))
+	}
+	if contentTypePrefix != "" {
+		fmt.Fprintf(&b, "  <input type=\"text\" name=\"Content-Type\" value=\"%s\">\n", html.EscapeString(contentTypePrefix))
+	}
+	b.WriteString("  <input type=\"file\" name=\"file\">\n")
+	b.WriteString("  <input type=\"submit\" value=\"Upload\">\n")
+	b.WriteString("</form>\n")
+	return b.String()
+}
+
+func init() {
+	Commands.Register(new(postPolicyCommand), "post-policy")
+}
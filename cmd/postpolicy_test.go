@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/iij/dagtools/client"
+	"github.com/iij/dagtools/env"
+	"github.com/iij/dagtools/ini"
+	"github.com/golang/mock/gomock"
+)
+
+func TestPostPolicyUsage(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(postPolicyCommand)
+	c.Init(&e)
+	usage := c.Usage()
+	if !strings.HasPrefix(usage, "Command Usage:") {
+		t.Errorf("Failed to get a post-policy command usage. usage: %q", usage)
+	}
+}
+
+func TestPostPolicy(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(postPolicyCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	mock.EXPECT().PresignPostPolicy(gomock.Any()).Do(func(p *client.PostPolicy) {
+		if p.Bucket != "mybucket" || p.KeyPrefix != "uploads/" || p.MaxContentLength != 1024 || p.ContentType != "image/png" {
+			t.Errorf("Should pass the conditions. %v", p)
+		}
+	}).Return(nil, errors.New("ok"))
+	c.cli = mock
+	err := c.Run(parseArgs("-max-size 1024 -content-type image/png mybucket:uploads/"))
+	if err == nil || err.Error() != "ok" {
+		t.Error("unknown error:", err)
+	}
+}
+
+func TestPostPolicyUnsupportedFormat(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(postPolicyCommand)
+	c.Init(&e)
+	err := c.Run(parseArgs("-format xml mybucket"))
+	if err == nil || err.Error() != `unsupported format: "xml"` {
+		t.Error("unknown error:", err)
+	}
+}
+
+func TestFormHTML(t *testing.T) {
+	form := &client.PostForm{
+		URL:    "https://storage-dag.iijgio.com/mybucket/",
+		Fields: map[string]string{"key": "uploads/${filename}", "policy": "cG9saWN5", "signature": "c2ln+/="},
+	}
+	expected := `<form action="https://storage-dag.iijgio.com/mybucket/" method="post" enctype="multipart/form-data">
+  <input type="hidden" name="key" value="uploads/${filename}">
+  <input type="hidden" name="policy" value="cG9saWN5">
+  <input type="hidden" name="signature" value="c2ln+/=">
+  <input type="text" name="Content-Type" value="image/">
+  <input type="file" name="file">
+  <input type="submit" value="Upload">
+</form>
+`
+	if actual := formHTML(form, "image/"); actual != expected {
+		t.Errorf("The HTML form was not match. %s", actual)
+	}
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/iij/dagtools/client"
+	"github.com/iij/dagtools/env"
+)
+
+var (
+	presignMethods = map[string]bool{
+		"GET":  true,
+		"PUT":  true,
+		"HEAD": true,
+	}
+)
+
+type presignCommand struct {
+	env         *env.Environment
+	cli         client.StorageClient
+	opts        *flag.FlagSet
+	expires     time.Duration
+	method      string
+	contentType string
+}
+
+func (c *presignCommand) Description() string {
+	return "generate a presigned URL of an object"
+}
+
+func (c *presignCommand) Usage() string {
+	return fmt.Sprintf(`Command Usage:
+  presign [-expires=<duration>] [-method=GET|PUT|HEAD] [-content-type=<type>] <bucket>:<key>
+
+Options:
+%s`, OptionUsage(c.opts))
+}
+
+func (c *presignCommand) Init(env *env.Environment) (err error) {
+	c.env = env
+	c.cli, _ = client.NewStorageClient(env)
+	opts := flag.NewFlagSet("presign", flag.ExitOnError)
+	opts.DurationVar(&c.expires, "expires", time.Hour, "expiration time of the URL (e.g. 30m, 1h, 168h)")
+	opts.StringVar(&c.method, "method", "GET", "HTTP method to be allowed (GET, PUT, HEAD)")
+	opts.StringVar(&c.contentType, "content-type", "", "Content-Type which a PUT request must have")
+	opts.Usage = func() {
+		fmt.Fprintln(os.Stdout, c.Usage())
+	}
+	c.opts = opts
+	return
+}
+
+func (c *presignCommand) Run(args []string) (err error) {
+	c.opts.Parse(args)
+	argv := c.opts.Args()
+	if len(argv) != 1 {
+		return ErrArgument
+	}
+	bucket, key, remote := splitResource(argv[0])
+	if !remote || bucket == "" || key == "" {
+		return ErrArgument
+	}
+	if strings.HasPrefix(key, "/") {
+		return errors.New("object key must not include the slash(/) at the beginning of the value")
+	}
+	method := strings.ToUpper(c.method)
+	if !presignMethods[method] {
+		return fmt.Errorf("unsupported method: %q", c.method)
+	}
+	if c.expires <= 0 {
+		return fmt.Errorf("invalid expires: %v", c.expires)
+	}
+	header := http.Header{}
+	if c.contentType != "" {
+		header.Set("Content-Type", c.contentType)
+	}
+	expires := time.Now().Add(c.expires)
+	u, err := c.cli.PresignURL(method, bucket, key, expires, header)
+	if err != nil {
+		return err
+	}
+	if c.env.Verbose {
+		fmt.Fprintf(os.Stderr, "presign: %s %s:%s (expires: %s)\n", method, bucket, key, expires.Format(time.RFC3339))
+	}
+	fmt.Println(u)
+	return
+}
+
+func init() {
+	Commands.Register(new(presignCommand), "presign")
+}
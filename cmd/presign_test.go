@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iij/dagtools/client"
+	"github.com/iij/dagtools/env"
+	"github.com/iij/dagtools/ini"
+	"github.com/golang/mock/gomock"
+)
+
+func TestPresignUsage(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(presignCommand)
+	c.Init(&e)
+	usage := c.Usage()
+	if !strings.HasPrefix(usage, "Command Usage:") {
+		t.Errorf("Failed to get a presign command usage. usage: %q", usage)
+	}
+}
+
+func TestPresignPut(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(presignCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	now := time.Now()
+	mock.EXPECT().PresignURL("PUT", "mybucket", "foo/bar", gomock.Any(), http.Header{"Content-Type": {"text/csv"}}).Do(
+		func(method, bucket, key string, expires time.Time, header http.Header) {
+			if d := expires.Sub(now); d < 30*time.Minute || d > 31*time.Minute {
+				t.Errorf("Should expire in 30 minutes. %v", d)
+			}
+		}).Return("", errors.New("ok"))
+	c.cli = mock
+	err := c.Run(parseArgs("-expires 30m -method put -content-type text/csv mybucket:foo/bar"))
+	if err == nil || err.Error() != "ok" {
+		t.Error("unknown error:", err)
+	}
+}
+
+func TestPresignUnsupportedMethod(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(presignCommand)
+	c.Init(&e)
+	err := c.Run(parseArgs("-method DELETE mybucket:foo"))
+	if err == nil || err.Error() != `unsupported method: "DELETE"` {
+		t.Error("unknown error:", err)
+	}
+}
+
+func TestPresignIllegalArgument(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(presignCommand)
+	c.Init(&e)
+	if err := c.Run(parseArgs("mybucket")); err != ErrArgument {
+		t.Error("unknown error:", err)
+	}
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iij/dagtools/client"
+)
+
+const (
+	progressBarWidth = 20
+	// progressTTYInterval is the interval to redraw the progress bar on a terminal.
+	progressTTYInterval = 200 * time.Millisecond
+	// progressLineInterval is the interval to print the progress lines to a non-terminal.
+	progressLineInterval = 10 * time.Second
+)
+
+// progressBar is a client.ProgressListener which renders the progress of the transfers.
+// On a terminal, it redraws a progress bar with the rate and ETA. Otherwise, it prints a line periodically.
+type progressBar struct {
+	out      io.Writer
+	tty      bool
+	interval time.Duration
+	mu       sync.Mutex
+	start    time.Time
+	// total is the sum of the sizes of the objects, and unknown is the bytes of the objects of unknown size in progress.
+	total   int64
+	unknown map[string]int64
+	// done is the bytes transferred or skipped, and transferred is the bytes transferred (to calculate the rate).
+	done        int64
+	transferred int64
+	objects     int
+	completed   int
+	current     string
+	stop        chan struct{}
+	wg          sync.WaitGroup
+}
+
+// newProgressBar returns a progressBar which writes to the file. It draws a bar if the file is a terminal.
+func newProgressBar(f *os.File) *progressBar {
+	b := &progressBar{out: f, interval: progressLineInterval, unknown: make(map[string]int64)}
+	if isTerminal(f) {
+		b.tty = true
+		b.interval = progressTTYInterval
+	}
+	return b
+}
+
+// ProgressChanged updates the progress by the event.
+func (b *progressBar) ProgressChanged(e client.ProgressEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	name := e.Bucket + ":" + e.Key
+	switch e.Type {
+	case client.TransferStarted:
+		b.objects++
+		if e.Size < 0 {
+			b.unknown[name] = 0
+		} else {
+			b.total += e.Size
+		}
+		b.current = name
+	case client.BytesTransferred:
+		b.done += e.Bytes
+		b.transferred += e.Bytes
+		if e.Size < 0 {
+			b.unknown[name] += e.Bytes
+		}
+	case client.PartSkipped:
+		b.done += e.Bytes
+	case client.TransferCompleted, client.TransferFailed:
+		if e.Size < 0 {
+			// the size is known at the end of the upload
+			b.total += b.unknown[name]
+			delete(b.unknown, name)
+		}
+		if e.Type == client.TransferCompleted {
+			b.completed++
+		}
+	}
+}
+
+// Start starts to render the progress periodically.
+func (b *progressBar) Start() {
+	b.start = time.Now()
+	b.stop = make(chan struct{})
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		t := time.NewTicker(b.interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				b.render()
+			case <-b.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops rendering and prints the final progress.
+func (b *progressBar) Stop() {
+	close(b.stop)
+	b.wg.Wait()
+	b.render()
+	if b.tty {
+		fmt.Fprintln(b.out, "")
+	}
+}
+
+func (b *progressBar) render() {
+	line := b.String()
+	if b.tty {
+		fmt.Fprintf(b.out, "\r%s\033[K", line)
+	} else {
+		fmt.Fprintln(b.out, "progress:", line)
+	}
+}
+
+// String returns the progress, e.g., "[=========>          ]  45% 120MB/266MB 12.3MB/s ETA 12s (1/3) mybucket:foo".
+func (b *progressBar) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var rate float64
+	if elapsed := time.Since(b.start).Seconds(); elapsed > 0 && b.transferred > 0 {
+		rate = float64(b.transferred) / elapsed
+	}
+	var s []string
+	if len(b.unknown) == 0 && b.total > 0 {
+		ratio := float64(b.done) / float64(b.total)
+		if ratio > 1 {
+			ratio = 1
+		}
+		if b.tty {
+			s = append(s, bar(ratio))
+		}
+		s = append(s, fmt.Sprintf("%3.0f%%", ratio*100),
+			fmt.Sprintf("%s/%s", HumanReadableBytes(uint64(b.done)), HumanReadableBytes(uint64(b.total))),
+			fmt.Sprintf("%s/s", HumanReadableBytes(uint64(rate))))
+		if rate > 0 && b.done < b.total {
+			eta := time.Duration(float64(b.total-b.done)/rate) * time.Second
+			s = append(s, "ETA "+eta.String())
+		}
+	} else {
+		s = append(s, HumanReadableBytes(uint64(b.done)), fmt.Sprintf("%s/s", HumanReadableBytes(uint64(rate))))
+	}
+	s = append(s, fmt.Sprintf("(%d/%d)", b.completed, b.objects))
+	if b.current != "" {
+		s = append(s, b.current)
+	}
+	return strings.Join(s, " ")
+}
+
+// bar returns a progress bar of the ratio, e.g., "[=========>          ]".
+func bar(ratio float64) string {
+	n := int(ratio * progressBarWidth)
+	if n >= progressBarWidth {
+		return "[" + strings.Repeat("=", progressBarWidth) + "]"
+	}
+	return "[" + strings.Repeat("=", n) + ">" + strings.Repeat(" ", progressBarWidth-n-1) + "]"
+}
+
+// showProgress returns the client which reports the progress of the transfers to stderr,
+// and a function to stop reporting.
+func showProgress(cli client.StorageClient) (client.StorageClient, func()) {
+	b := newProgressBar(os.Stderr)
+	b.Start()
+	return cli.WithProgress(b), b.Stop
+}
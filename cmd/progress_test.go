@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iij/dagtools/client"
+)
+
+func TestProgressBar(t *testing.T) {
+	var out bytes.Buffer
+	b := &progressBar{out: &out, interval: time.Hour, unknown: make(map[string]int64)}
+	b.Start()
+	b.ProgressChanged(client.ProgressEvent{Type: client.TransferStarted, Bucket: "mybucket", Key: "foo", Size: 4096})
+	b.ProgressChanged(client.ProgressEvent{Type: client.PartSkipped, Bucket: "mybucket", Key: "foo", Size: 4096, PartNumber: 1, Bytes: 1024})
+	b.ProgressChanged(client.ProgressEvent{Type: client.BytesTransferred, Bucket: "mybucket", Key: "foo", Size: 4096, PartNumber: 2, Bytes: 1024})
+	if s := b.String(); !strings.HasPrefix(s, " 50% 2.0KB/4.0KB ") || !strings.HasSuffix(s, "(0/1) mybucket:foo") {
+		t.Errorf("Unexpected progress: %q", s)
+	}
+	b.tty = true
+	if s := b.String(); !strings.HasPrefix(s, "[==========>         ]  50% ") {
+		t.Errorf("Unexpected progress bar: %q", s)
+	}
+	b.tty = false
+	b.ProgressChanged(client.ProgressEvent{Type: client.TransferStarted, Bucket: "mybucket", Key: "bar", Size: -1})
+	b.ProgressChanged(client.ProgressEvent{Type: client.BytesTransferred, Bucket: "mybucket", Key: "bar", Size: -1, Bytes: 2048})
+	if s := b.String(); !strings.HasPrefix(s, "4.0KB ") || !strings.HasSuffix(s, "(0/2) mybucket:bar") {
+		t.Errorf("Unexpected progress of an object of unknown size: %q", s)
+	}
+	b.ProgressChanged(client.ProgressEvent{Type: client.TransferCompleted, Bucket: "mybucket", Key: "bar", Size: -1})
+	b.Stop()
+	if s := out.String(); !strings.HasPrefix(s, "progress:  67% 4.0KB/6.0KB ") {
+		t.Errorf("Unexpected progress line: %q", s)
+	}
+}
+
+func TestProgressBarNotTerminal(t *testing.T) {
+	// a character device which is not a terminal
+	f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if b := newProgressBar(f); b.tty || b.interval != progressLineInterval {
+		t.Errorf("%s should not be regarded as a terminal.", os.DevNull)
+	}
+}
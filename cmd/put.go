@@ -20,6 +20,8 @@ type putCommand struct {
 	opts      *flag.FlagSet
 	recursive bool
 	uploadId  string
+	limitRate string
+	progress  bool
 }
 
 func (c *putCommand) Description() string {
@@ -45,6 +47,8 @@ func (c *putCommand) Init(env *env.Environment) (err error) {
 	opts := flag.NewFlagSet("put", flag.ExitOnError)
 	opts.BoolVar(&c.recursive, "r", false, "recursively upload")
 	opts.StringVar(&c.uploadId, "upload-id", "", "identifier of multipart upload")
+	opts.StringVar(&c.limitRate, "limit-rate", "", "limit the transfer rate in bytes per second (e.g., 512K, 20M)")
+	opts.BoolVar(&c.progress, "progress", false, "show the progress of the transfers to stderr")
 	opts.Usage = func() {
 		fmt.Fprintln(os.Stdout, c.Usage())
 	}
@@ -62,6 +66,14 @@ func (c *putCommand) Run(args []string) (err error) {
 	}
 	c.opts.Parse(args)
 	argv := c.opts.Args()
+	if c.cli, err = limitRate(c.cli, c.limitRate); err != nil {
+		return err
+	}
+	if c.progress {
+		var stop func()
+		c.cli, stop = showProgress(c.cli)
+		defer stop()
+	}
 
 	// Target resource: "{Bucket}:{Key}"
 	slice := strings.Split(argv[len(argv)-1], ":")
@@ -82,7 +94,10 @@ func (c *putCommand) Run(args []string) (err error) {
 			if key == "" {
 				return ErrArgument
 			}
-			return c.cli.Upload(bucket, key, r, nil)
+			if err = c.cli.Upload(bucket, key, r, nil); err != nil {
+				printAbortCommand(err)
+			}
+			return err
 		}
 	}
 	// PUT Bucket
@@ -124,10 +139,12 @@ func (c *putCommand) putFileOrDirectory(root string, bucket string, key string)
 		}
 		if c.uploadId != "" {
 			if err = c.cli.ResumeUploadFile(bucket, target, c.uploadId, fd, nil); err != nil {
+				printResumeCommand(err, root)
 				return err
 			}
 		} else {
 			if err = c.cli.UploadFile(bucket, target, fd, nil); err != nil {
+				printResumeCommand(err, root)
 				return err
 			}
 		}
@@ -170,6 +187,7 @@ func (c *putCommand) putFileOrDirectory(root string, bucket string, key string)
 						fmt.Fprintf(os.Stdout, "put: %s -> %s:%s\n", path, bucket, target)
 					}
 					if err = c.cli.UploadFile(bucket, target, fd, nil); err != nil {
+						printResumeCommand(err, path)
 						return err
 					}
 					return nil
@@ -184,6 +202,23 @@ func (c *putCommand) putFileOrDirectory(root string, bucket string, key string)
 	return
 }
 
+// printResumeCommand prints the command to resume the multipart upload if it has not been aborted.
+func printResumeCommand(err error, filename string) {
+	if e, ok := err.(*client.MultipartUploadError); ok {
+		fmt.Fprintf(os.Stderr, "The multipart upload has not been aborted. To resume it, run:\n  dagtools put -upload-id=%s %s %s:%s\n",
+			e.Upload.UploadID, filename, e.Upload.Bucket, e.Upload.Key)
+	}
+}
+
+// printAbortCommand prints the command to abort the multipart upload from the standard input,
+// which cannot be resumed because the input has been consumed.
+func printAbortCommand(err error) {
+	if e, ok := err.(*client.MultipartUploadError); ok {
+		fmt.Fprintf(os.Stderr, "The multipart upload has not been aborted. It cannot be resumed from the standard input. To abort it, run:\n  dagtools uploads rm %s:%s:%s\n",
+			e.Upload.Bucket, e.Upload.Key, e.Upload.UploadID)
+	}
+}
+
 func init() {
 	Commands.Register(new(putCommand), "put")
 }
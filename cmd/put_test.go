@@ -183,3 +183,41 @@ func TestPutDirectoryWithoutOption(t *testing.T) {
 		t.Errorf("Error message was not match. \"test_files/\" is a directory (not uploaded) != %v", err.Error())
 	}
 }
+
+func TestPutFileWithLimitRate(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(putCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	limited := client.NewMockStorageClient(ctrl)
+	mock.EXPECT().WithRateLimit(int64(20*1024*1024), int64(20*1024*1024)).Return(limited)
+	limited.EXPECT().UploadFile("mybucket", "test.txt", fileMatcher{"test_files/test-00.txt"}, nil).Return(nil)
+	c.cli = mock
+	if err := c.Run(parseArgs("-limit-rate=20M test_files/test-00.txt mybucket:test.txt")); err != nil {
+		t.Error(err)
+	}
+	c.Init(&e)
+	if err := c.Run(parseArgs("-limit-rate=fast test_files/test-00.txt mybucket:test.txt")); err == nil {
+		t.Error("Should fail with an invalid rate.")
+	}
+}
+
+func TestPutFileWithProgress(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(putCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	reporting := client.NewMockStorageClient(ctrl)
+	mock.EXPECT().WithProgress(gomock.Any()).Return(reporting)
+	reporting.EXPECT().UploadFile("mybucket", "test.txt", fileMatcher{"test_files/test-00.txt"}, nil).Return(nil)
+	c.cli = mock
+	if err := c.Run(parseArgs("-progress test_files/test-00.txt mybucket:test.txt")); err != nil {
+		t.Error(err)
+	}
+}
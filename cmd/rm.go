@@ -68,7 +68,7 @@ func (c *rmCommand) removeBucket(bucket string) (err error) {
 			}
 			if res.HasErrors() {
 				for _, e := range res.Errors {
-					c.env.Logger.Println(e.String())
+					c.env.EventLogger.Log(env.LevelError, e.String())
 				}
 			}
 			if listing.IsTruncated {
@@ -124,7 +124,7 @@ func (c *rmCommand) removeObject(bucket string, prefix string) (num int, err err
 			}
 			if res.HasErrors() {
 				for _, e := range res.Errors {
-					c.env.Logger.Println(e.String())
+					c.env.EventLogger.Log(env.LevelError, e.String())
 				}
 			}
 			if listing != nil && listing.IsTruncated {
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/iij/dagtools/env"
+)
+
+// notifyInterrupt cancels the context on SIGINT or SIGTERM so that a running command can stop its transfers cleanly.
+// The second signal terminates the process immediately. The returned function stops the notification.
+func notifyInterrupt(e *env.Environment, cancel context.CancelFunc) (stop func()) {
+	sig := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case s := <-sig:
+			e.Logger.Printf("Received a signal (%v). Stopping the command ...", s)
+			fmt.Fprintln(os.Stderr, "\nInterrupted. Cleaning up ... (press Ctrl-C again to exit immediately)")
+			cancel()
+		case <-done:
+			return
+		}
+		select {
+		case <-sig:
+			os.Exit(130)
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(sig)
+		close(done)
+	}
+}
+
+// notifyHangup reopens the log file on SIGHUP, so that the file can be rotated by an external tool (e.g., logrotate).
+// The returned function stops the notification.
+func notifyHangup(e *env.Environment) (stop func()) {
+	sig := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sig, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-sig:
+				if err := e.ReopenLog(); err != nil {
+					fmt.Fprintf(os.Stderr, "[Error] failed to reopen the log file: %v\n", err)
+					continue
+				}
+				e.Logger.Println("Reopened the log file (SIGHUP).")
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sig)
+		close(done)
+	}
+}
+
+// interrupted returns true if the command has been interrupted.
+func interrupted(e *env.Environment) bool {
+	return e.Context != nil && e.Context.Err() != nil
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/iij/dagtools/env"
+)
+
+// PrintStats prints the transfer statistics in the format (table or json).
+func PrintStats(w io.Writer, s env.StatsSummary, format string) error {
+	switch format {
+	case "json":
+		b, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "METHOD\tSTATUS\tREQUESTS")
+		for _, r := range s.Requests {
+			status := strconv.Itoa(r.Status)
+			if r.Status == 0 {
+				// no response (e.g., a network error)
+				status = "-"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%d\n", r.Method, status, r.Count)
+		}
+		fmt.Fprintln(tw, "")
+		fmt.Fprintf(tw, "Retries:\t%d\n", s.Retries)
+		fmt.Fprintf(tw, "Bytes sent:\t%d\n", s.BytesSent)
+		fmt.Fprintf(tw, "Bytes received:\t%d\n", s.BytesReceived)
+		fmt.Fprintf(tw, "Objects transferred:\t%d\n", s.ObjectsTransferred)
+		fmt.Fprintf(tw, "Objects skipped:\t%d\n", s.ObjectsSkipped)
+		fmt.Fprintf(tw, "Elapsed time:\t%d ms\n", s.ElapsedMs)
+		fmt.Fprintf(tw, "Throughput:\t%s/s\n", HumanReadableBytes(uint64(s.Throughput)))
+		return tw.Flush()
+	}
+	return fmt.Errorf("invalid stats format: %q (table, json)", format)
+}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"bufio"
 	"errors"
 	"flag"
 	"fmt"
@@ -17,11 +16,13 @@ import (
 )
 
 type syncCommand struct {
-	env     *env.Environment
-	cli     client.StorageClient
-	opts    *flag.FlagSet
-	dryRun  bool
-	verbose bool
+	env       *env.Environment
+	cli       client.StorageClient
+	opts      *flag.FlagSet
+	dryRun    bool
+	verbose   bool
+	limitRate string
+	progress  bool
 }
 
 func (c *syncCommand) Description() string {
@@ -43,6 +44,8 @@ func (c *syncCommand) Init(env *env.Environment) (err error) {
 	opts := flag.NewFlagSet("sync", flag.ExitOnError)
 	opts.BoolVar(&c.dryRun, "n", false, "show what would have been transferred(dry-run)")
 	opts.BoolVar(&c.verbose, "v", env.Verbose, "verbose mode")
+	opts.StringVar(&c.limitRate, "limit-rate", "", "limit the transfer rate in bytes per second (e.g., 512K, 20M)")
+	opts.BoolVar(&c.progress, "progress", false, "show the progress of the transfers to stderr")
 	opts.Usage = func() {
 		fmt.Fprintln(os.Stdout, c.Usage())
 	}
@@ -64,6 +67,9 @@ func (c *syncCommand) SyncLocalToDag(bucket string, prefix string, dir string) (
 		if err != nil {
 			return err
 		}
+		if interrupted(c.env) {
+			return c.env.Context.Err()
+		}
 		if !info.IsDir() {
 			fd, err := os.Open(path)
 			if err != nil {
@@ -99,6 +105,7 @@ func (c *syncCommand) SyncLocalToDag(bucket string, prefix string, dir string) (
 					if c.env.Debug {
 						c.env.Logger.Printf("no change. %s:%s = %s", bucket, key, path)
 					}
+					c.env.Stats.AddSkipped()
 					return nil
 				}
 			}
@@ -108,7 +115,7 @@ func (c *syncCommand) SyncLocalToDag(bucket string, prefix string, dir string) (
 				lastModified := strconv.Itoa(int(fstat.ModTime().Unix()))
 				metadata.AddUserMetadata("last_modified", lastModified)
 				if err := c.cli.UploadFile(bucket, key, fd, metadata); err != nil {
-					c.env.Logger.Printf(fmt.Sprintf("Failed to upload %q. %s", path, err))
+					c.env.EventLogger.Log(env.LevelError, fmt.Sprintf("Failed to upload %q. %s", path, err))
 					fmt.Fprintf(os.Stderr, "[Error] %v\n", err)
 				} else if c.env.Verbose {
 					fmt.Printf("put: %s -> %s:%s\n", path, bucket, key)
@@ -152,6 +159,9 @@ func (c *syncCommand) SyncDagToLocal(bucket string, prefix string, dir string) (
 			break
 		}
 		for _, o := range listing.Summaries {
+			if interrupted(c.env) {
+				return c.env.Context.Err()
+			}
 			name := strings.Replace(o.Key, prefix, "", 1)
 			target := strings.Replace(dir+name, string(os.PathSeparator), "/", -1)
 			if name == "" {
@@ -201,6 +211,7 @@ func (c *syncCommand) WriteFile(o *client.ObjectSummary, bucket string, target s
 						if c.env.Debug {
 							c.env.Logger.Printf("no change. %s = %s:%s", target, bucket, o.Key)
 						}
+						c.env.Stats.AddSkipped()
 						return nil
 					}
 				}
@@ -208,34 +219,15 @@ func (c *syncCommand) WriteFile(o *client.ObjectSummary, bucket string, target s
 		}
 	} else {
 		if c.env.Debug {
-			c.env.Logger.Print(err)
+			c.env.EventLogger.Log(env.LevelError, err.Error())
 		}
 	}
-	r, err := c.cli.GetObject(bucket, o.Key)
-	if err != nil {
-		c.env.Logger.Printf("Failed to get object: %s/%s. %s", bucket, o.Key, err)
-		fmt.Fprintf(os.Stderr, "[Error] %v\n", err)
-		return
-	}
-	defer r.Close()
-	file, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, 0644)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "[Error] %v\n", err)
-		return
-	}
-	defer file.Close()
-	br := bufio.NewReader(r)
-	bw := bufio.NewWriter(file)
-	defer func(o *client.ObjectSummary) {
-		bw.Flush()
-		os.Chtimes(target, o.LastModified, o.LastModified)
-	}(o)
 	if c.env.Verbose {
 		fmt.Printf("get: %s:%s -> %s\n", bucket, o.Key, target)
 	}
-	if _, err := br.WriteTo(bw); err != nil {
+	if err = downloadFile(c.env, c.cli, bucket, o.Key, target, false); err != nil {
+		c.env.EventLogger.Log(env.LevelError, fmt.Sprintf("Failed to get object: %s/%s. %s", bucket, o.Key, err))
 		fmt.Fprintf(os.Stderr, "[Error] %v\n", err)
-		c.env.Logger.Printf("Failed to write file: %s. %s", target, err)
 	}
 	return
 }
@@ -249,6 +241,14 @@ func (c *syncCommand) Run(args []string) (err error) {
 	)
 	c.opts.Parse(args)
 	argv := c.opts.Args()
+	if c.cli, err = limitRate(c.cli, c.limitRate); err != nil {
+		return err
+	}
+	if c.progress {
+		var stop func()
+		c.cli, stop = showProgress(c.cli)
+		defer stop()
+	}
 	for _, arg := range argv {
 		if strings.Contains(arg, ":") {
 			slice := strings.Split(arg, ":")
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !windows
+// +build !linux,!darwin,!windows
+
+package cmd
+
+import "os"
+
+// isTerminal returns true if the file is a character device.
+// It is not exact on this platform, e.g., /dev/null is also regarded as a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && (fi.Mode()&os.ModeCharDevice) != 0
+}
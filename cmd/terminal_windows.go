@@ -0,0 +1,12 @@
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+// isTerminal returns true if the file is a console.
+func isTerminal(f *os.File) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(f.Fd()), &mode) == nil
+}
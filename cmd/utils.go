@@ -4,8 +4,16 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"math"
+	"os"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/iij/dagtools/client"
+	"github.com/iij/dagtools/env"
 )
 
 var (
@@ -44,3 +52,131 @@ func OptionUsage(f *flag.FlagSet) string {
 	})
 	return usage
 }
+
+// parseRange parses a byte range (e.g., "0-1023", "1024-", "-1024") and returns an offset and a length.
+// The length is 0 for an open-ended range and the offset is negative for a suffix range.
+func parseRange(s string) (off, length int64, err error) {
+	invalid := fmt.Errorf("invalid range: %q", s)
+	slice := strings.SplitN(s, "-", 2)
+	if len(slice) != 2 || (slice[0] == "" && slice[1] == "") {
+		return 0, 0, invalid
+	}
+	if slice[0] == "" {
+		n, err := strconv.ParseInt(slice[1], 10, 64)
+		if err != nil || n <= 0 {
+			return 0, 0, invalid
+		}
+		return -n, 0, nil
+	}
+	if off, err = strconv.ParseInt(slice[0], 10, 64); err != nil || off < 0 {
+		return 0, 0, invalid
+	}
+	if slice[1] == "" {
+		return off, 0, nil
+	}
+	last, err := strconv.ParseInt(slice[1], 10, 64)
+	if err != nil || last < off {
+		return 0, 0, invalid
+	}
+	return off, last - off + 1, nil
+}
+
+// splitResource splits "<bucket>:<key>" into a bucket and a key.
+// If arg does not contain a colon, it is treated as a local path.
+func splitResource(arg string) (bucket, key string, remote bool) {
+	if !strings.Contains(arg, ":") {
+		return "", arg, false
+	}
+	slice := strings.Split(arg, ":")
+	return slice[0], strings.Join(slice[1:], ":"), true
+}
+
+// walkObjects calls fn with each object under the prefix and the key relative to the prefix.
+func walkObjects(cli client.StorageClient, bucket, prefix string, fn func(o client.ObjectSummary, name string) error) (err error) {
+	listing, err := cli.ListObjects(bucket, prefix, "", "", 1000)
+	if err != nil {
+		return err
+	}
+	for {
+		if listing == nil || len(listing.Summaries) < 1 {
+			break
+		}
+		for _, o := range listing.Summaries {
+			if prefix != "" && prefix != o.Key && !strings.HasSuffix(prefix, "/") && !strings.HasPrefix(o.Key, prefix+"/") {
+				continue
+			}
+			name := strings.TrimLeft(strings.TrimPrefix(o.Key, prefix), "/")
+			if err = fn(o, name); err != nil {
+				return err
+			}
+		}
+		if listing.IsTruncated {
+			if listing, err = cli.NextListObjects(listing); err != nil {
+				return err
+			}
+		} else {
+			listing = nil
+		}
+	}
+	return
+}
+
+// openInput opens the file of the argument, or stdin if no argument is given and it is not a terminal.
+// It returns ErrArgument if there are too many arguments or nothing to read.
+func openInput(argv []string) (io.ReadCloser, error) {
+	switch len(argv) {
+	case 1:
+		return os.Open(argv[0])
+	case 0:
+		stat, _ := os.Stdin.Stat()
+		if stat == nil || (stat.Mode()&os.ModeCharDevice) != 0 {
+			return nil, ErrArgument
+		}
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+	return nil, ErrArgument
+}
+
+// downloadFile downloads an object to the target file. If resume is true, it resumes a previous download.
+// On failure, the file is removed if it did not exist before and has no progress to resume.
+// If the command has been interrupted, the partially downloaded file is also removed unless resume is true.
+func downloadFile(e *env.Environment, cli client.StorageClient, bucket, key, target string, resume bool) (err error) {
+	_, err = os.Stat(target)
+	created := os.IsNotExist(err)
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	if resume {
+		err = cli.ResumeDownloadFile(bucket, key, out)
+	} else {
+		err = cli.DownloadFile(bucket, key, out)
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		stateFile := client.DownloadStateFilename(target)
+		_, serr := os.Stat(stateFile)
+		switch partial := serr == nil; {
+		case partial && interrupted(e) && !resume:
+			os.Remove(stateFile)
+			os.Remove(target)
+		case !partial && created:
+			os.Remove(target)
+		}
+	}
+	return
+}
+
+// limitRate returns the client with the limits of the -limit-rate option, or cli itself if the option is not specified.
+func limitRate(cli client.StorageClient, rate string) (client.StorageClient, error) {
+	if rate == "" {
+		return cli, nil
+	}
+	n, err := client.ParseRate(rate)
+	if err != nil {
+		return cli, err
+	}
+	return cli.WithRateLimit(n, n), nil
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"io/ioutil"
 	"os"
 	"strings"
 	"testing"
@@ -59,3 +60,75 @@ func parseArgs(args string) []string {
 	}
 	return _args
 }
+
+func TestParseRange(t *testing.T) {
+	cases := []struct {
+		s      string
+		off    int64
+		length int64
+	}{
+		{"0-1023", 0, 1024},
+		{"1024-", 1024, 0},
+		{"-1024", -1024, 0},
+		{"10-10", 10, 1},
+	}
+	for _, tc := range cases {
+		off, length, err := parseRange(tc.s)
+		if err != nil {
+			t.Errorf("Failed to parse a range %q. %v", tc.s, err)
+		}
+		if off != tc.off || length != tc.length {
+			t.Errorf("%q: (%d, %d) != (%d, %d)", tc.s, tc.off, tc.length, off, length)
+		}
+	}
+	for _, s := range []string{"", "-", "100", "10-5", "-0", "a-b", "-1-2"} {
+		if _, _, err := parseRange(s); err == nil {
+			t.Errorf("Should return an error. %q", s)
+		}
+	}
+}
+
+func TestOpenInput(t *testing.T) {
+	f, err := ioutil.TempFile("", "dagtools-input")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("<AccessControlPolicy/>")
+	f.Close()
+
+	in, err := openInput([]string{f.Name()})
+	if err != nil {
+		t.Fatalf("Failed to open the file. %v", err)
+	}
+	b, _ := ioutil.ReadAll(in)
+	in.Close()
+	if string(b) != "<AccessControlPolicy/>" {
+		t.Errorf("Unexpected content: %q", b)
+	}
+	if _, err = openInput([]string{f.Name(), f.Name()}); err != ErrArgument {
+		t.Errorf("Should return ErrArgument. %v", err)
+	}
+	if _, err = openInput([]string{f.Name() + ".notfound"}); err == nil {
+		t.Error("Should return an error.")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = stdin }()
+	w.WriteString("stdin")
+	w.Close()
+	if in, err = openInput(nil); err != nil {
+		t.Fatalf("Failed to read stdin. %v", err)
+	}
+	b, _ = ioutil.ReadAll(in)
+	in.Close()
+	r.Close()
+	if string(b) != "stdin" {
+		t.Errorf("Unexpected content: %q", b)
+	}
+}
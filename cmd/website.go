@@ -0,0 +1,414 @@
+package cmd
+
+import (
+	"bufio"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iij/dagtools/client"
+	"github.com/iij/dagtools/env"
+)
+
+var (
+	websiteSubCommands = map[string]bool{
+		"put":    true,
+		"rm":     true,
+		"cat":    true,
+		"deploy": true,
+	}
+	// websiteMimeTypes complements mime.TypeByExtension for files commonly used in websites.
+	websiteMimeTypes = map[string]string{
+		".ico":   "image/vnd.microsoft.icon",
+		".map":   "application/json",
+		".txt":   "text/plain; charset=utf-8",
+		".woff":  "font/woff",
+		".woff2": "font/woff2",
+	}
+)
+
+type websiteCommand struct {
+	env              *env.Environment
+	cli              client.StorageClient
+	opts             *flag.FlagSet
+	index            string
+	errorDocument    string
+	redirects        string
+	cacheControl     string
+	htmlCacheControl string
+	dryRun           bool
+	force            bool
+	delete           bool
+}
+
+func (c *websiteCommand) Description() string {
+	return "manage a bucket website configuration and deploy a website (put, cat, rm, deploy)"
+}
+
+func (c *websiteCommand) Usage() string {
+	return fmt.Sprintf(`Command Usage:
+  website cat <bucket>
+  website rm <bucket>
+  website put <bucket> <file>
+  website put <bucket> < <file>
+  website put -index=<suffix> [-error=<key>] <bucket>
+  website deploy [-n] [-f] [-delete] [-redirects=<file>] <dir> <bucket>[:<prefix>]
+
+The redirects file has a pair of a path and a location (a path or an URL) per line:
+  /old/page.html /new/page.html
+  /blog/         https://blog.example.com/
+
+Options:
+%s`, OptionUsage(c.opts))
+}
+
+func (c *websiteCommand) Init(env *env.Environment) (err error) {
+	c.env = env
+	c.cli, _ = client.NewStorageClient(env)
+	opts := flag.NewFlagSet("website", flag.ExitOnError)
+	opts.StringVar(&c.index, "index", "", "put: an index document suffix (e.g. index.html)")
+	opts.StringVar(&c.errorDocument, "error", "", "put: an error document key (e.g. error.html)")
+	opts.StringVar(&c.redirects, "redirects", "", "deploy: a file to create redirect objects from")
+	opts.StringVar(&c.cacheControl, "cache-control", "max-age=86400", "deploy: Cache-Control of files except HTML")
+	opts.StringVar(&c.htmlCacheControl, "html-cache-control", "no-cache", "deploy: Cache-Control of HTML files")
+	opts.BoolVar(&c.dryRun, "n", false, "deploy: show what would have been transferred(dry-run)")
+	opts.BoolVar(&c.force, "f", false, "deploy: upload all files even if they have not been changed")
+	opts.BoolVar(&c.delete, "delete", false, "deploy: delete objects which do not exist in the directory")
+	opts.Usage = func() {
+		fmt.Fprintln(os.Stdout, c.Usage())
+	}
+	c.opts = opts
+	return
+}
+
+func (c *websiteCommand) Run(args []string) (err error) {
+	if len(args) < 1 {
+		return ErrArgument
+	}
+	command := args[0]
+	if !websiteSubCommands[command] {
+		return fmt.Errorf("website's sub-command not found: %q", command)
+	}
+	c.opts.Parse(args[1:])
+	argv := c.opts.Args()
+	switch command {
+	case "put":
+		if len(argv) < 1 || argv[0] == "" {
+			return ErrArgument
+		}
+		var website *client.WebsiteConfiguration
+		if c.index != "" || c.errorDocument != "" {
+			if len(argv) != 1 {
+				return ErrArgument
+			}
+			website = &client.WebsiteConfiguration{IndexDocument: c.index, ErrorDocument: c.errorDocument}
+		} else if website, err = c.readConfiguration(argv[1:]); err != nil {
+			return err
+		}
+		if err = website.Validate(); err != nil {
+			return err
+		}
+		return c.cli.PutBucketWebsite(argv[0], website)
+	case "cat":
+		if len(argv) != 1 || argv[0] == "" {
+			return ErrArgument
+		}
+		website, err := c.cli.GetBucketWebsite(argv[0])
+		if err != nil {
+			return err
+		}
+		b, err := xml.MarshalIndent(website, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(xml.Header + string(b))
+	case "rm":
+		if len(argv) != 1 || argv[0] == "" {
+			return ErrArgument
+		}
+		return c.cli.DeleteBucketWebsite(argv[0])
+	case "deploy":
+		if len(argv) != 2 {
+			return ErrArgument
+		}
+		bucket, prefix := argv[1], ""
+		if strings.Contains(bucket, ":") {
+			bucket, prefix, _ = splitResource(bucket)
+		}
+		if bucket == "" {
+			return ErrArgument
+		}
+		if strings.HasPrefix(prefix, "/") {
+			return errors.New("object key must not include the slash(/) at the beginning of the value")
+		}
+		return c.deploy(argv[0], bucket, prefix)
+	}
+	return
+}
+
+// readConfiguration reads a website configuration in XML from the file or stdin.
+func (c *websiteCommand) readConfiguration(argv []string) (website *client.WebsiteConfiguration, err error) {
+	in, err := openInput(argv)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	website = new(client.WebsiteConfiguration)
+	if err = xml.NewDecoder(in).Decode(website); err != nil {
+		return nil, fmt.Errorf("invalid website configuration: %v", err)
+	}
+	return website, nil
+}
+
+// deploy uploads files in the directory which have been changed, and creates redirect objects.
+func (c *websiteCommand) deploy(dir, bucket, prefix string) (err error) {
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("%q is not a directory", dir)
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	redirects := make(map[string]string)
+	if c.redirects != "" {
+		if redirects, err = readRedirects(c.redirects, prefix); err != nil {
+			return err
+		}
+	}
+	var remoteKeys []string
+	remote := make(map[string]client.ObjectSummary)
+	err = walkObjects(c.cli, bucket, prefix, func(o client.ObjectSummary, name string) error {
+		remote[o.Key] = o
+		remoteKeys = append(remoteKeys, o.Key)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	failed := 0
+	local := make(map[string]bool)
+	err = filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if interrupted(c.env) {
+			return c.env.Context.Err()
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		key := prefix + filepath.ToSlash(rel)
+		local[key] = true
+		if _, ok := redirects[key]; ok {
+			c.env.Logger.Printf("%q is overridden by a redirect.", p)
+			return nil
+		}
+		if err := c.deployFile(p, bucket, key, remote[key]); err != nil {
+			c.env.EventLogger.Log(env.LevelError, fmt.Sprintf("Failed to deploy %q to %s:%s. %s", p, bucket, key, err))
+			fmt.Fprintf(os.Stderr, "[Error] %s: %v\n", p, err)
+			failed++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	var redirectKeys []string
+	for key := range redirects {
+		redirectKeys = append(redirectKeys, key)
+	}
+	sort.Strings(redirectKeys)
+	for _, key := range redirectKeys {
+		if interrupted(c.env) {
+			return c.env.Context.Err()
+		}
+		if err := c.putRedirect(bucket, key, redirects[key]); err != nil {
+			c.env.EventLogger.Log(env.LevelError, fmt.Sprintf("Failed to create a redirect %s:%s. %s", bucket, key, err))
+			fmt.Fprintf(os.Stderr, "[Error] %s:%s: %v\n", bucket, key, err)
+			failed++
+		}
+	}
+	if c.delete {
+		for _, key := range remoteKeys {
+			if local[key] || redirects[key] != "" || strings.HasSuffix(key, "/") {
+				continue
+			}
+			if !c.dryRun {
+				if err := c.cli.DeleteObject(bucket, key); err != nil {
+					c.env.EventLogger.Log(env.LevelError, fmt.Sprintf("Failed to delete %s:%s. %s", bucket, key, err))
+					fmt.Fprintf(os.Stderr, "[Error] %s:%s: %v\n", bucket, key, err)
+					failed++
+					continue
+				}
+			}
+			if c.env.Verbose {
+				fmt.Printf("delete: %s:%s%s\n", bucket, key, c.dryRunSuffix())
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to deploy %d object(s)", failed)
+	}
+	return nil
+}
+
+// deployFile uploads a file with Content-Type and Cache-Control unless the object has the same content.
+func (c *websiteCommand) deployFile(filename, bucket, key string, o client.ObjectSummary) (err error) {
+	fd, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+	fi, err := fd.Stat()
+	if err != nil {
+		return err
+	}
+	if !c.force && o.Key != "" && fi.Size() == o.Size {
+		same, err := c.sameContent(fd, fi, bucket, o)
+		if err != nil {
+			return err
+		}
+		if same {
+			if c.env.Debug {
+				c.env.Logger.Printf("no change. %s:%s = %s", bucket, key, filename)
+			}
+			return nil
+		}
+	}
+	metadata := &client.ObjectMetadata{
+		ContentType:  websiteContentType(filename),
+		CacheControl: c.cacheControl,
+	}
+	metadata.AddUserMetadata("last_modified", strconv.FormatInt(fi.ModTime().Unix(), 10))
+	if strings.HasPrefix(metadata.ContentType, "text/html") {
+		metadata.CacheControl = c.htmlCacheControl
+	}
+	if !c.dryRun {
+		if err = c.cli.UploadFile(bucket, key, fd, metadata); err != nil {
+			return err
+		}
+	}
+	if c.env.Verbose {
+		fmt.Printf("put: %s -> %s:%s%s\n", filename, bucket, key, c.dryRunSuffix())
+	}
+	return nil
+}
+
+// sameContent reports whether the file has the same content as the object of the same size.
+// The ETag of an object uploaded in parts (e.g., "<hash>-N") is not the MD5 of the content,
+// so such an object is compared by the modification time recorded in the metadata as sync does.
+func (c *websiteCommand) sameContent(fd *os.File, fi os.FileInfo, bucket string, o client.ObjectSummary) (bool, error) {
+	etag := strings.Trim(o.ETag, `"`)
+	if strings.Contains(etag, "-") {
+		m, err := c.cli.GetObjectMetadata(bucket, o.Key)
+		if err != nil || m == nil || m.Metadata == nil {
+			return false, err
+		}
+		return m.Metadata.GetUserMetadata("last_modified") == strconv.FormatInt(fi.ModTime().Unix(), 10), nil
+	}
+	h := md5.New()
+	if _, err := io.Copy(h, fd); err != nil {
+		return false, err
+	}
+	if _, err := fd.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	return hex.EncodeToString(h.Sum(nil)) == etag, nil
+}
+
+// putRedirect creates an empty object which redirects requests to the location.
+func (c *websiteCommand) putRedirect(bucket, key, location string) (err error) {
+	if !c.dryRun {
+		fd, err := os.Open(os.DevNull)
+		if err != nil {
+			return err
+		}
+		defer fd.Close()
+		metadata := &client.ObjectMetadata{
+			ContentType:             "text/html; charset=utf-8",
+			CacheControl:            c.htmlCacheControl,
+			WebsiteRedirectLocation: location,
+		}
+		if err = c.cli.PutObject(bucket, key, fd, metadata); err != nil {
+			return err
+		}
+	}
+	if c.env.Verbose {
+		fmt.Printf("redirect: %s:%s -> %s%s\n", bucket, key, location, c.dryRunSuffix())
+	}
+	return nil
+}
+
+func (c *websiteCommand) dryRunSuffix() string {
+	if c.dryRun {
+		return " (dry-run)"
+	}
+	return ""
+}
+
+// readRedirects reads a redirects file and returns a map of object keys to redirect locations.
+// A path ending with a slash is redirected with "index.html" appended.
+func readRedirects(filename, prefix string) (redirects map[string]string, err error) {
+	fd, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	redirects = make(map[string]string)
+	scanner := bufio.NewScanner(fd)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: invalid redirect: %q", filename, n, line)
+		}
+		src, location := strings.TrimLeft(fields[0], "/"), fields[1]
+		if !strings.HasPrefix(location, "/") && !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+			return nil, fmt.Errorf("%s:%d: location must be a path or an URL: %q", filename, n, location)
+		}
+		if src == "" || strings.HasSuffix(src, "/") {
+			src += "index.html"
+		}
+		redirects[prefix+src] = location
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	return redirects, nil
+}
+
+// websiteContentType returns a Content-Type of the file for a website (with charset for text files).
+func websiteContentType(filename string) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if t, ok := websiteMimeTypes[ext]; ok {
+		return t
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+func init() {
+	Commands.Register(new(websiteCommand), "website")
+}
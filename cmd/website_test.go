@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/iij/dagtools/client"
+	"github.com/iij/dagtools/env"
+	"github.com/iij/dagtools/ini"
+	"github.com/golang/mock/gomock"
+)
+
+func TestWebsiteUsage(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(websiteCommand)
+	c.Init(&e)
+	usage := c.Usage()
+	if !strings.HasPrefix(usage, "Command Usage:") {
+		t.Errorf("Failed to get a website command usage. usage: %q", usage)
+	}
+}
+
+func TestPutWebsiteIndex(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(websiteCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	mock.EXPECT().PutBucketWebsite("mybucket", &client.WebsiteConfiguration{IndexDocument: "index.html", ErrorDocument: "404.html"}).Return(nil)
+	c.cli = mock
+	if err := c.Run(parseArgs("put -index=index.html -error=404.html mybucket")); err != nil {
+		t.Error("unknown error:", err)
+	}
+}
+
+func TestCatWebsite(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(websiteCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	mock.EXPECT().GetBucketWebsite("mybucket").Return(nil, errors.New("ok"))
+	c.cli = mock
+	err := c.Run(parseArgs("cat mybucket"))
+	if err == nil || err.Error() != "ok" {
+		t.Error("unknown error:", err)
+	}
+}
+
+func TestRmWebsite(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(websiteCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	mock.EXPECT().DeleteBucketWebsite("mybucket").Return(errors.New("ok"))
+	c.cli = mock
+	err := c.Run(parseArgs("rm mybucket"))
+	if err == nil || err.Error() != "ok" {
+		t.Error("unknown error:", err)
+	}
+}
+
+func TestDeployWebsite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "website")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	site := filepath.Join(dir, "site")
+	os.MkdirAll(filepath.Join(site, "css"), 0755)
+	ioutil.WriteFile(filepath.Join(site, "index.html"), []byte("<html></html>"), 0644)
+	ioutil.WriteFile(filepath.Join(site, "css", "style.css"), []byte("body {}"), 0644)
+	ioutil.WriteFile(filepath.Join(site, "old.html"), []byte("old"), 0644)
+	// an image uploaded in parts
+	ioutil.WriteFile(filepath.Join(site, "logo.png"), []byte("png"), 0644)
+	logo, _ := os.Stat(filepath.Join(site, "logo.png"))
+	index, _ := os.Stat(filepath.Join(site, "index.html"))
+	redirects := filepath.Join(dir, "redirects")
+	ioutil.WriteFile(redirects, []byte("# moved pages\n/old.html /index.html\n/blog/ https://blog.example.com/\n"), 0644)
+
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := env.Environment{Config: config}
+	e.Init()
+	c := new(websiteCommand)
+	c.Init(&e)
+	ctrl := gomock.NewController(t)
+	mock := client.NewMockStorageClient(ctrl)
+	listing := &client.ObjectListing{
+		Name: "mybucket",
+		Summaries: []client.ObjectSummary{
+			// md5("body {}")
+			{Key: "www/css/style.css", Size: 7, ETag: `"fcdce6b6d6e2175f6406869882f6f1ce"`},
+			{Key: "www/logo.png", Size: 3, ETag: `"d41d8cd98f00b204e9800998ecf8427e-2"`},
+			{Key: "www/stale.html", Size: 5},
+		},
+	}
+	mock.EXPECT().ListObjects("mybucket", "www/", "", "", 1000).Return(listing, nil)
+	logoMetadata := new(client.ObjectMetadata)
+	logoMetadata.AddUserMetadata("last_modified", strconv.FormatInt(logo.ModTime().Unix(), 10))
+	mock.EXPECT().GetObjectMetadata("mybucket", "www/logo.png").Return(&client.Object{Metadata: logoMetadata}, nil)
+	indexMetadata := &client.ObjectMetadata{
+		ContentType:  "text/html; charset=utf-8",
+		CacheControl: "no-cache",
+	}
+	indexMetadata.AddUserMetadata("last_modified", strconv.FormatInt(index.ModTime().Unix(), 10))
+	mock.EXPECT().UploadFile("mybucket", "www/index.html", gomock.Any(), indexMetadata).Return(nil)
+	mock.EXPECT().PutObject("mybucket", "www/old.html", gomock.Any(), &client.ObjectMetadata{
+		ContentType:             "text/html; charset=utf-8",
+		CacheControl:            "no-cache",
+		WebsiteRedirectLocation: "/index.html",
+	}).Return(nil)
+	mock.EXPECT().PutObject("mybucket", "www/blog/index.html", gomock.Any(), &client.ObjectMetadata{
+		ContentType:             "text/html; charset=utf-8",
+		CacheControl:            "no-cache",
+		WebsiteRedirectLocation: "https://blog.example.com/",
+	}).Return(errors.New("dummy"))
+	mock.EXPECT().DeleteObject("mybucket", "www/stale.html").Return(nil)
+	c.cli = mock
+	err = c.Run(parseArgs("deploy -delete -redirects=" + redirects + " " + site + " mybucket:www"))
+	if err == nil || err.Error() != "failed to deploy 1 object(s)" {
+		t.Error("unknown error:", err)
+	}
+}
+
+func TestReadRedirectsInvalidLocation(t *testing.T) {
+	fd, err := ioutil.TempFile("", "redirects")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(fd.Name())
+	fd.WriteString("/old.html new.html\n")
+	fd.Close()
+	if _, err = readRedirects(fd.Name(), ""); err == nil || !strings.Contains(err.Error(), "location must be a path or an URL") {
+		t.Error("unknown error:", err)
+	}
+}
+
+func TestWebsiteContentType(t *testing.T) {
+	for filename, expected := range map[string]string{
+		"index.HTML":     "text/html; charset=utf-8",
+		"app.js":         "text/javascript; charset=utf-8",
+		"font.woff2":     "font/woff2",
+		"unknown.binary": "application/octet-stream",
+	} {
+		if actual := websiteContentType(filename); actual != expected {
+			t.Errorf("Content-Type of %s was not match. %s != %s", filename, expected, actual)
+		}
+	}
+}
@@ -1,12 +1,13 @@
 package env
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
-	"regexp"
 	"runtime"
 	"time"
 
@@ -18,70 +19,161 @@ var (
 	Version = "1.7.0-dev"
 )
 
+const (
+	// EnvProfile is a name of the environment variable to select a profile.
+	EnvProfile = "DAGTOOLS_PROFILE"
+)
+
 // Environment defines parameters for dagtools
 type Environment struct {
 	Version     string
 	Verbose     bool
 	Debug       bool
+	Profile     string
 	Concurrency int
 	Config      *ini.Config
 	Logger      *log.Logger
+	// EventLogger records leveled events with fields. If nil, Init creates one from [logging].
+	EventLogger EventLogger
+	Context     context.Context
+	// Stats collects the statistics of the requests and the transfers. If nil, Init creates one.
+	Stats *Stats
+	// StatsFormat is the format (table or json) to print Stats after a command, or "" not to print.
+	StatsFormat string
 	startTime   time.Time
+	// logFile is the file of [logging] type = file.
+	logFile *RotatingFile
+	// sources are the environment variables which override the options ("section.key" to the name).
+	sources map[string]string
 }
 
 // Init do initializing Environment
 func (e *Environment) Init() (err error) {
 	e.Version = Version
+	// profile
+	if e.Profile == "" {
+		e.Profile = os.Getenv(EnvProfile)
+	}
+	e.applyOverrides()
+	// an invalid value is reported after the logger is set up
+	var configErr error
 	// debug
 	if !e.Debug {
-		e.Debug = e.Config.GetBool("dagtools", "debug", false)
+		e.Debug, configErr = e.Config.Bool("dagtools", "debug", false)
 	}
 	// verbose
 	if !e.Verbose {
-		e.Verbose = e.Config.GetBool("dagtools", "verbose", true)
+		var verr error
+		if e.Verbose, verr = e.Config.Bool("dagtools", "verbose", true); configErr == nil {
+			configErr = verr
+		}
 	}
 	// Set startTime
 	e.startTime = time.Now()
-	var logger *log.Logger
-	t := e.Config.Get("logging", "type", "none")
-	flag := log.LstdFlags
+	if e.Stats == nil {
+		e.Stats = NewStats()
+	}
+	if e.EventLogger == nil {
+		if e.EventLogger, err = e.newEventLogger(); err != nil {
+			return
+		}
+	}
+	// free-form messages are recorded as events of the info level
+	w := NewLogWriter(e.EventLogger, LevelInfo)
+	e.Logger = log.New(w, "", 0)
+	log.SetOutput(w)
+	log.SetFlags(0)
+	var cerr error
+	if e.Concurrency, cerr = e.Config.Int("dagtools", "concurrency", 1); configErr == nil {
+		configErr = cerr
+	}
+	runtime.GOMAXPROCS(e.Concurrency)
+	if e.Profile != "" && e.profileSectionName() == "" {
+		return fmt.Errorf("profile not found: %q", e.Profile)
+	}
+	if configErr != nil {
+		return configErr
+	}
+
 	if e.Debug {
-		flag = flag | log.Lmicroseconds
+		e.EventLogger.Log(LevelDebug, "Environment: "+e.String())
+		e.dumpConfig()
 	}
-	switch t {
+	return nil
+}
+
+// newEventLogger returns an EventLogger of [logging] type, format and level.
+func (e *Environment) newEventLogger() (EventLogger, error) {
+	var out io.Writer
+	switch e.Config.Get("logging", "type", "none") {
 	case "file":
-		filename := e.Config.Get("logging", "file", "dagtools.log")
-		file, _ := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
-		logger = log.New(file, "", flag)
-		log.SetOutput(file)
+		file, err := e.newLogFile()
+		if err != nil {
+			return nil, err
+		}
+		e.logFile = file
+		out = file
 	case "stdout":
-		logger = log.New(os.Stdout, "", flag)
-		log.SetOutput(os.Stdout)
+		out = os.Stdout
 	case "stderr":
-		logger = log.New(os.Stderr, "", flag)
-		log.SetOutput(os.Stderr)
+		out = os.Stderr
 	case "", "none":
-		out := ioutil.Discard
-		logger = log.New(out, "", 0)
-		log.SetOutput(out)
+		out = ioutil.Discard
 	default:
-		err = errors.New("environment::logging type is invalid")
-		return
+		return nil, errors.New("environment::logging type is invalid")
+	}
+	level, err := ParseLevel(e.Config.Get("logging", "level", "info"))
+	if err != nil {
+		return nil, err
 	}
-	e.Logger = logger
-	e.Concurrency = e.Config.GetInt("dagtools", "concurrency", 1)
-	runtime.GOMAXPROCS(e.Concurrency)
-
 	if e.Debug {
-		logger.Println("Environment:", e.String())
-		exp, _ := regexp.Compile(`"secretAccessKey":"[^"]+"`)
-		logger.Println("Config:", exp.ReplaceAllString(e.Config.String(), `"secretAccessKey":"..."`))
+		level = LevelDebug
 	}
-	return nil
+	switch e.Config.Get("logging", "format", "text") {
+	case "text":
+		flag := log.LstdFlags
+		if e.Debug {
+			flag = flag | log.Lmicroseconds
+		}
+		return &TextLogger{Logger: log.New(out, "", flag), Level: level}, nil
+	case "json":
+		return &JSONLogger{Out: out, Level: level}, nil
+	}
+	return nil, errors.New("environment::logging format is invalid")
 }
 
 func (e *Environment) String() string {
-	return fmt.Sprintf("{Version: %s, Verbose: %v, Debug: %v, Concurrency: %d}", e.Version, e.Verbose, e.Debug, e.Concurrency)
+	return fmt.Sprintf("{Version: %s, Verbose: %v, Debug: %v, Profile: %q, Concurrency: %d}", e.Version, e.Verbose, e.Debug, e.Profile, e.Concurrency)
+}
+
+// StorageSection returns the section of the storage settings.
+// It is [profile NAME] or [storage:NAME] if a profile is selected, otherwise [dagrin] or [storage].
+func (e *Environment) StorageSection() *ini.Section {
+	return e.Config.Section(e.storageSectionName())
+}
+
+// storageSectionName returns the name of the section of the storage settings.
+func (e *Environment) storageSectionName() string {
+	if name := e.profileSectionName(); name != "" {
+		return name
+	}
+	if e.Config.HasSection("dagrin") {
+		return "dagrin"
+	}
+	return "storage"
+}
+
+// profileSectionName returns the name of the section of the profile, or "" if not found.
+func (e *Environment) profileSectionName() string {
+	if e.Profile == "" {
+		return ""
+	}
+	for _, name := range []string{"profile " + e.Profile, "storage:" + e.Profile} {
+		if e.Config.HasSection(name) {
+			return name
+		}
+	}
+	return ""
 }
 
 // GetElapsedTimeMs returns elapsed time (milli seconds)
@@ -89,7 +181,47 @@ func (e *Environment) GetElapsedTimeMs() int64 {
 	return (time.Now().UnixNano() - e.startTime.UnixNano()) / 1000000
 }
 
+// newLogFile opens the log file of [logging] file with the rotation settings.
+func (e *Environment) newLogFile() (*RotatingFile, error) {
+	file := &RotatingFile{Filename: e.Config.Get("logging", "file", "dagtools.log")}
+	var (
+		maxAge int
+		err    error
+	)
+	if file.MaxSize, err = e.Config.Int64("logging", "maxSize", 0); err != nil {
+		return nil, err
+	}
+	if file.MaxBackups, err = e.Config.Int("logging", "maxBackups", 0); err != nil {
+		return nil, err
+	}
+	if maxAge, err = e.Config.Int("logging", "maxAge", 0); err != nil {
+		return nil, err
+	}
+	file.MaxAge = time.Duration(maxAge) * 24 * time.Hour
+	if file.Compress, err = e.Config.Bool("logging", "compress", false); err != nil {
+		return nil, err
+	}
+	if err = file.Open(); err != nil {
+		return nil, fmt.Errorf("failed to open the log file: %v", err)
+	}
+	return file, nil
+}
+
+// ReopenLog reopens the log file, e.g., on SIGHUP after the file is moved by logrotate.
+// It does nothing if the logs are not written to a file.
+func (e *Environment) ReopenLog() error {
+	if e.logFile == nil {
+		return nil
+	}
+	return e.logFile.Reopen()
+}
+
 // Close do cleanup Environment
 func (e *Environment) Close() {
-	e.Logger.Println("Environment closed.")
+	if e.Logger != nil {
+		e.Logger.Println("Environment closed.")
+	}
+	if e.logFile != nil {
+		e.logFile.Close()
+	}
 }
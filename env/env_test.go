@@ -1,6 +1,16 @@
 package env
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/iij/dagtools/ini"
@@ -59,3 +69,294 @@ func TestInvalidEnvironment(t *testing.T) {
 		t.Error("Environment::Debug set true in config.")
 	}
 }
+
+func TestProfileEnvironment(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	config.Set("storage", "endpoint", "storage.example.com")
+	config.Set("profile staging", "endpoint", "staging.example.com")
+	config.Set("storage:production", "endpoint", "production.example.com")
+	e := Environment{
+		Config: config,
+	}
+	e.Init()
+	if e.StorageSection().Get("endpoint", "") != "storage.example.com" {
+		t.Error("Environment::StorageSection is [storage] without a profile.")
+	}
+	e = Environment{
+		Profile: "staging",
+		Config:  config,
+	}
+	if err := e.Init(); err != nil {
+		t.Errorf("Environment::Init failed with an existing profile. %v", err)
+	}
+	if e.StorageSection().Get("endpoint", "") != "staging.example.com" {
+		t.Error("Environment::StorageSection is [profile staging] with the staging profile.")
+	}
+	os.Setenv(EnvProfile, "production")
+	defer os.Unsetenv(EnvProfile)
+	e = Environment{
+		Config: config,
+	}
+	e.Init()
+	if e.StorageSection().Get("endpoint", "") != "production.example.com" {
+		t.Error("Environment::StorageSection is [storage:production] with DAGTOOLS_PROFILE.")
+	}
+}
+
+func TestProfileNotFound(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	e := Environment{
+		Profile: "staging",
+		Config:  config,
+	}
+	if err := e.Init(); err == nil {
+		t.Error("Environment::Init should fail with an unknown profile.")
+	}
+}
+
+func TestInvalidConcurrency(t *testing.T) {
+	config := ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	config.Set("dagtools", "concurrency", "four")
+	e := Environment{Config: &config}
+	if _, ok := e.Init().(ini.InvalidValue); !ok {
+		t.Error("Should return an error of the invalid value.")
+	}
+	if e.Concurrency != 1 {
+		t.Errorf("Should use the default concurrency: %d", e.Concurrency)
+	}
+}
+
+func TestOverrideEnvName(t *testing.T) {
+	for _, v := range [][3]string{
+		{"storage", "endpoint", "DAGTOOLS_STORAGE_ENDPOINT"},
+		{"storage", "accessKeyId", "DAGTOOLS_STORAGE_ACCESS_KEY_ID"},
+		{"storage", "multipartChunkSize", "DAGTOOLS_STORAGE_MULTIPART_CHUNK_SIZE"},
+		{"dagtools", "concurrency", "DAGTOOLS_DAGTOOLS_CONCURRENCY"},
+		{"dagtools", "tempDir", "DAGTOOLS_DAGTOOLS_TEMP_DIR"},
+	} {
+		if name := OverrideEnvName(v[0], v[1]); name != v[2] {
+			t.Errorf("OverrideEnvName(%q, %q) should be %q: %q", v[0], v[1], v[2], name)
+		}
+	}
+}
+
+func TestOverrideEnvironment(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	config.Set("dagtools", "concurrency", "2")
+	config.Set("storage", "endpoint", "storage.example.com")
+	config.Set("profile staging", "endpoint", "staging.example.com")
+	os.Setenv("DAGTOOLS_DAGTOOLS_CONCURRENCY", "4")
+	defer os.Unsetenv("DAGTOOLS_DAGTOOLS_CONCURRENCY")
+	os.Setenv("DAGTOOLS_STORAGE_ENDPOINT", "override.example.com")
+	defer os.Unsetenv("DAGTOOLS_STORAGE_ENDPOINT")
+	e := Environment{
+		Profile: "staging",
+		Config:  config,
+	}
+	if err := e.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if e.Concurrency != 4 {
+		t.Errorf("Environment::Concurrency should be overridden by the environment variable: %d", e.Concurrency)
+	}
+	if endpoint := e.StorageSection().Get("endpoint", ""); endpoint != "override.example.com" {
+		t.Errorf("The endpoint of the profile should be overridden by the environment variable: %q", endpoint)
+	}
+	if endpoint := config.Get("storage", "endpoint", ""); endpoint != "storage.example.com" {
+		t.Errorf("[storage] should not be changed while the profile is selected: %q", endpoint)
+	}
+	if e.sources["profile staging.endpoint"] != "$DAGTOOLS_STORAGE_ENDPOINT" {
+		t.Errorf("The source of the value should be recorded: %v", e.sources)
+	}
+}
+
+func TestOverrideEveryOption(t *testing.T) {
+	for section, keys := range ConfigSchema {
+		for key := range keys {
+			name := OverrideEnvName(section, key)
+			os.Setenv(name, "override")
+			defer os.Unsetenv(name)
+		}
+	}
+	e := Environment{Config: &ini.Config{}}
+	e.applyOverrides()
+	for section, keys := range ConfigSchema {
+		for key := range keys {
+			if v := e.Config.Get(section, key, ""); v != "override" {
+				t.Errorf("[%s] %s should be overridden by %s: %q", section, key, OverrideEnvName(section, key), v)
+			}
+		}
+	}
+}
+
+func TestOverrideWithoutConfigFile(t *testing.T) {
+	os.Setenv("DAGTOOLS_STORAGE_ENDPOINT", "override.example.com")
+	defer os.Unsetenv("DAGTOOLS_STORAGE_ENDPOINT")
+	e := Environment{
+		Config: &ini.Config{},
+	}
+	if err := e.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if endpoint := e.StorageSection().Get("endpoint", ""); endpoint != "override.example.com" {
+		t.Errorf("The endpoint should be set by the environment variable: %q", endpoint)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for name, level := range map[string]Level{"debug": LevelDebug, "info": LevelInfo, "WARN": LevelWarn, "error": LevelError} {
+		if l, err := ParseLevel(name); err != nil || l != level {
+			t.Errorf("ParseLevel(%q) should be %v: %v, %v", name, level, l, err)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("ParseLevel should fail with an unknown level.")
+	}
+}
+
+func TestTextLogger(t *testing.T) {
+	var out bytes.Buffer
+	l := &TextLogger{Logger: log.New(&out, "", 0), Level: LevelInfo}
+	l.Log(LevelDebug, "hidden")
+	l.Log(LevelWarn, "request", "bucket", "mybucket", "key", "a b", "status", 503)
+	if s := out.String(); s != "[warn] request bucket=mybucket key=\"a b\" status=503\n" {
+		t.Errorf("Unexpected output: %q", s)
+	}
+}
+
+func TestJSONLogger(t *testing.T) {
+	var out bytes.Buffer
+	l := &JSONLogger{Out: &out, Level: LevelWarn}
+	l.Log(LevelInfo, "hidden")
+	l.Log(LevelError, "failed", "error", errors.New("dummy"), "status", 500)
+	var event map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &event); err != nil {
+		t.Fatalf("Should write an event as JSON: %q", out.String())
+	}
+	if event["level"] != "error" || event["msg"] != "failed" || event["error"] != "dummy" || event["status"] != float64(500) {
+		t.Errorf("Unexpected event: %v", event)
+	}
+}
+
+func TestInitEventLogger(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	config.Set("logging", "format", "json")
+	config.Set("logging", "level", "warn")
+	e := Environment{Config: config}
+	if err := e.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if l, ok := e.EventLogger.(*JSONLogger); !ok || l.Level != LevelWarn {
+		t.Errorf("Environment::EventLogger should be a JSONLogger of [logging] level. %#v", e.EventLogger)
+	}
+	var out bytes.Buffer
+	e = Environment{Config: config, EventLogger: &TextLogger{Logger: log.New(&out, "", 0)}}
+	if err := e.Init(); err != nil {
+		t.Fatal(err)
+	}
+	e.Logger.Println("hello")
+	if out.String() != "[info] hello\n" {
+		t.Errorf("Environment::Logger should write to the EventLogger. %q", out.String())
+	}
+	config.Set("logging", "format", "xml")
+	e = Environment{Config: config}
+	if err := e.Init(); err == nil {
+		t.Error("Environment::Init should fail with an unknown format.")
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "dagtools-log")
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "dagtools.log")
+	f := &RotatingFile{Filename: filename, MaxSize: 10, MaxBackups: 2, Compress: true}
+	if err := f.Open(); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"line1\n", "line2\n", "line3\n", "line4\n"} {
+		f.Write([]byte(line))
+	}
+	f.Close()
+	b, _ := ioutil.ReadFile(filename)
+	if string(b) != "line4\n" {
+		t.Errorf("The file should be rotated before exceeding MaxSize. %q", string(b))
+	}
+	backups, _ := filepath.Glob(filename + ".*.gz")
+	if len(backups) != 2 {
+		t.Errorf("MaxBackups compressed files should be kept. %v", backups)
+	}
+	sort.Strings(backups)
+	in, _ := os.Open(backups[len(backups)-1])
+	defer in.Close()
+	r, err := gzip.NewReader(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := ioutil.ReadAll(r); string(b) != "line3\n" {
+		t.Errorf("The newest backup should have the previous logs. %q", string(b))
+	}
+}
+
+func TestRotatingFileReopen(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "dagtools-log")
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "dagtools.log")
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	config.Set("logging", "type", "file")
+	config.Set("logging", "file", filename)
+	e := Environment{Config: config}
+	if err := e.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	os.Rename(filename, filename+".old")
+	if err := e.ReopenLog(); err != nil {
+		t.Fatal(err)
+	}
+	e.Logger.Println("reopened")
+	if b, _ := ioutil.ReadFile(filename); !strings.Contains(string(b), "reopened") {
+		t.Errorf("The logs should be written to the new file. %q", string(b))
+	}
+}
+
+func TestLogFileError(t *testing.T) {
+	config := &ini.Config{Filename: "dummy.ini", Sections: make(map[string]ini.Section)}
+	config.Set("logging", "type", "file")
+	config.Set("logging", "file", "/nonexistent/dagtools.log")
+	e := Environment{Config: config}
+	if err := e.Init(); err == nil {
+		t.Error("Environment::Init should fail if the log file cannot be opened.")
+	}
+}
+
+func TestStats(t *testing.T) {
+	s := NewStats()
+	s.AddRequest("PUT", 200)
+	s.AddRequest("GET", 503)
+	s.AddRequest("PUT", 200)
+	s.AddRequest("GET", 0)
+	s.AddRetry()
+	s.AddSent(100)
+	s.AddReceived(20)
+	s.AddTransferred()
+	s.AddSkipped()
+	s.AddSkipped()
+	sum := s.Summary()
+	expected := []RequestCount{{"GET", 0, 1}, {"GET", 503, 1}, {"PUT", 200, 2}}
+	if len(sum.Requests) != len(expected) {
+		t.Fatalf("Unexpected requests: %v", sum.Requests)
+	}
+	for i, r := range expected {
+		if sum.Requests[i] != r {
+			t.Errorf("Unexpected requests: %v", sum.Requests)
+		}
+	}
+	if sum.Retries != 1 || sum.BytesSent != 100 || sum.BytesReceived != 20 || sum.ObjectsTransferred != 1 || sum.ObjectsSkipped != 2 {
+		t.Errorf("Unexpected summary: %+v", sum)
+	}
+	var nilStats *Stats
+	nilStats.AddRequest("GET", 200)
+	if sum := nilStats.Summary(); len(sum.Requests) != 0 {
+		t.Errorf("A nil Stats should not count anything: %+v", sum)
+	}
+}
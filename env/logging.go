@@ -0,0 +1,146 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is a severity of a log event.
+type Level int
+
+// Log levels.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// ParseLevel returns the level of the name (error, warn, info or debug).
+func ParseLevel(name string) (Level, error) {
+	for l, n := range levelNames {
+		if strings.EqualFold(name, n) {
+			return l, nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("invalid log level: %q (error, warn, info, debug)", name)
+}
+
+// EventLogger records a log event with fields given as key-value pairs, e.g.,
+//
+//	logger.Log(env.LevelInfo, "request", "operation", "PutObject", "bucket", "mybucket", "status", 200)
+//
+// Set Environment.EventLogger to use another logger (e.g., an adapter of log/slog) instead of the built-in ones.
+type EventLogger interface {
+	Log(level Level, msg string, keysAndValues ...interface{})
+}
+
+// TextLogger is an EventLogger which writes an event as a line to Logger, e.g.,
+//
+//	2018/07/31 12:00:00 [info] request operation=PutObject bucket=mybucket status=200
+type TextLogger struct {
+	Logger *log.Logger
+	// Level is the minimum level of events to write.
+	Level Level
+}
+
+// Log writes the event if its level is enabled.
+func (l *TextLogger) Log(level Level, msg string, keysAndValues ...interface{}) {
+	if level < l.Level {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s", level, msg)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, value := fieldAt(keysAndValues, i)
+		s := fmt.Sprint(value)
+		if s == "" || strings.ContainsAny(s, " \t\r\n\"=") {
+			s = fmt.Sprintf("%q", s)
+		}
+		fmt.Fprintf(&b, " %s=%s", key, s)
+	}
+	l.Logger.Print(b.String())
+}
+
+// JSONLogger is an EventLogger which writes an event as a JSON object per line, e.g.,
+//
+//	{"time":"2018-07-31T12:00:00.000+09:00","level":"info","msg":"request","operation":"PutObject","status":200}
+type JSONLogger struct {
+	Out io.Writer
+	// Level is the minimum level of events to write.
+	Level Level
+	mu    sync.Mutex
+}
+
+// Log writes the event if its level is enabled.
+func (l *JSONLogger) Log(level Level, msg string, keysAndValues ...interface{}) {
+	if level < l.Level {
+		return
+	}
+	event := map[string]interface{}{
+		"time":  time.Now().Format("2006-01-02T15:04:05.000Z07:00"),
+		"level": level.String(),
+		"msg":   msg,
+	}
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, value := fieldAt(keysAndValues, i)
+		switch v := value.(type) {
+		case error:
+			value = v.Error()
+		case fmt.Stringer:
+			value = v.String()
+		}
+		event[key] = value
+	}
+	b, err := json.Marshal(event)
+	if err != nil {
+		b, _ = json.Marshal(map[string]interface{}{"time": event["time"], "level": event["level"], "msg": msg, "error": err.Error()})
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.Out.Write(append(b, '\n'))
+}
+
+// fieldAt returns the i-th key and the value. A key without a value has "!MISSING" as the value.
+func fieldAt(keysAndValues []interface{}, i int) (string, interface{}) {
+	key := fmt.Sprint(keysAndValues[i])
+	if i+1 >= len(keysAndValues) {
+		return key, "!MISSING"
+	}
+	return key, keysAndValues[i+1]
+}
+
+// NewLogWriter returns a writer which records each line as an event of the level,
+// to route a *log.Logger (e.g., Environment.Logger) to the EventLogger.
+func NewLogWriter(logger EventLogger, level Level) io.Writer {
+	return &logWriter{logger: logger, level: level}
+}
+
+type logWriter struct {
+	logger EventLogger
+	level  Level
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.logger.Log(w.level, strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
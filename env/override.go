@@ -0,0 +1,82 @@
+package env
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/iij/dagtools/ini"
+)
+
+const (
+	// EnvOverridePrefix is a prefix of the environment variables which override the options.
+	EnvOverridePrefix = "DAGTOOLS_"
+)
+
+// OverrideEnvName returns a name of the environment variable which overrides the option,
+// e.g., DAGTOOLS_STORAGE_MULTIPART_CHUNK_SIZE for [storage] multipartChunkSize.
+func OverrideEnvName(section, key string) string {
+	var b strings.Builder
+	b.WriteString(EnvOverridePrefix)
+	b.WriteString(strings.ToUpper(section))
+	b.WriteByte('_')
+	for i, c := range key {
+		if i > 0 && unicode.IsUpper(c) && !unicode.IsUpper(rune(key[i-1])) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(c))
+	}
+	return b.String()
+}
+
+// applyOverrides sets the values of the environment variables to the config, and records their sources.
+// The options of [storage] are applied to the section in use, i.e., the profile or [dagrin] if selected.
+func (e *Environment) applyOverrides() {
+	e.sources = make(map[string]string)
+	for section, keys := range ConfigSchema {
+		target := section
+		if section == "storage" {
+			target = e.storageSectionName()
+		}
+		for key := range keys {
+			name := OverrideEnvName(section, key)
+			value, ok := os.LookupEnv(name)
+			if !ok {
+				continue
+			}
+			if e.Config.Sections == nil {
+				e.Config.Sections = make(map[string]ini.Section)
+			}
+			e.Config.Set(target, key, value)
+			e.sources[target+"."+key] = "$" + name
+		}
+	}
+}
+
+// dumpConfig logs all options with the source of each value (the file or the environment variable).
+func (e *Environment) dumpConfig() {
+	var sections []string
+	for name := range e.Config.Sections {
+		sections = append(sections, name)
+	}
+	sort.Strings(sections)
+	for _, section := range sections {
+		var keys []string
+		for key := range e.Config.Sections[section] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := e.Config.Sections[section][key]
+			if key == "secretAccessKey" && value != "" {
+				value = "..."
+			}
+			source, ok := e.sources[section+"."+key]
+			if !ok {
+				source = e.Config.Filename
+			}
+			e.EventLogger.Log(LevelDebug, "config", "section", section, "key", key, "value", value, "source", source)
+		}
+	}
+}
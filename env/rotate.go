@@ -0,0 +1,195 @@
+package env
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// backupTimeFormat is the format of the time in the names of the rotated files, e.g., dagtools.log.20180731-120000.000
+	backupTimeFormat = "20060102-150405.000"
+	compressSuffix   = ".gz"
+)
+
+// RotatingFile is a log file which is rotated when it exceeds MaxSize.
+// The rotated files are named with the time of the rotation (e.g., dagtools.log.20180731-120000.000),
+// and removed if they are older than MaxAge or more than MaxBackups.
+type RotatingFile struct {
+	Filename string
+	// MaxSize is the maximum size of the file in bytes. 0 means no rotation.
+	MaxSize int64
+	// MaxBackups is the number of the rotated files to keep. 0 means all files are kept.
+	MaxBackups int
+	// MaxAge is the duration to keep the rotated files. 0 means all files are kept.
+	MaxAge time.Duration
+	// Compress makes the rotated files compressed with gzip.
+	Compress bool
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	wg   sync.WaitGroup
+	// bg serializes the compression and the removal of the rotated files.
+	bg sync.Mutex
+}
+
+// Open opens the file to append logs. The file is created if it does not exist.
+func (f *RotatingFile) Open() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.open()
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.Filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size = file, stat.Size()
+	return nil
+}
+
+// Write writes the log to the file, and rotates the file before it exceeds MaxSize.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	if f.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.MaxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Reopen closes and opens the file, e.g., after the file is moved by logrotate.
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+	return f.open()
+}
+
+// Close closes the file and waits for the compression of the rotated files.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+	f.wg.Wait()
+	return err
+}
+
+// rotate renames the current file to a backup and opens a new file.
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	t := time.Now()
+	backup := f.Filename + "." + t.Format(backupTimeFormat)
+	for exists(backup) || exists(backup+compressSuffix) {
+		t = t.Add(time.Millisecond)
+		backup = f.Filename + "." + t.Format(backupTimeFormat)
+	}
+	if err := os.Rename(f.Filename, backup); err != nil {
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		f.bg.Lock()
+		defer f.bg.Unlock()
+		if f.Compress {
+			if err := compressFile(backup); err != nil {
+				fmt.Fprintf(os.Stderr, "[Error] failed to compress the log file %s: %v\n", backup, err)
+			}
+		}
+		f.removeBackups()
+	}()
+	return nil
+}
+
+// removeBackups removes the rotated files older than MaxAge or more than MaxBackups.
+func (f *RotatingFile) removeBackups() {
+	if f.MaxBackups <= 0 && f.MaxAge <= 0 {
+		return
+	}
+	matches, _ := filepath.Glob(f.Filename + ".*")
+	type backup struct {
+		name string
+		t    time.Time
+	}
+	var backups []backup
+	for _, name := range matches {
+		s := strings.TrimSuffix(strings.TrimPrefix(name, f.Filename+"."), compressSuffix)
+		if t, err := time.ParseInLocation(backupTimeFormat, s, time.Local); err == nil {
+			backups = append(backups, backup{name, t})
+		}
+	}
+	// newest first
+	sort.Slice(backups, func(i, j int) bool { return backups[i].t.After(backups[j].t) })
+	for i, b := range backups {
+		if (f.MaxBackups > 0 && i >= f.MaxBackups) || (f.MaxAge > 0 && time.Since(b.t) > f.MaxAge) {
+			os.Remove(b.name)
+		}
+	}
+}
+
+func exists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+// compressFile compresses the file to name.gz and removes the original.
+func compressFile(name string) error {
+	in, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(name+compressSuffix, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	w := gzip.NewWriter(out)
+	if _, err = io.Copy(w, in); err == nil {
+		err = w.Close()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(name + compressSuffix)
+		return err
+	}
+	in.Close()
+	return os.Remove(name)
+}
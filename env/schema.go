@@ -0,0 +1,133 @@
+package env
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ConfigValidator returns an error if the value is invalid for the option.
+type ConfigValidator func(value string) error
+
+var (
+	// StorageConfigSchema is the options of [storage], which are also the options of [dagrin] and the profiles.
+	StorageConfigSchema = map[string]ConfigValidator{
+		"endpoint":              anyValue,
+		"accessKeyId":           anyValue,
+		"secretAccessKey":       anyValue,
+		"credentialsFile":       anyValue,
+		"credentialProcess":     anyValue,
+		"secure":                boolValue,
+		"insecureSkipVerify":    boolValue,
+		"multipartChunkSize":    intValue,
+		"retry":                 intValue,
+		"retryInterval":         intValue,
+		"maxBackoff":            intValue,
+		"retryOn":               statusCodesValue,
+		"abortOnFailure":        boolValue,
+		"maxIdleConns":          intValue,
+		"maxIdleConnsPerHost":   intValue,
+		"idleConnTimeout":       intValue,
+		"dialTimeout":           intValue,
+		"tlsHandshakeTimeout":   intValue,
+		"responseHeaderTimeout": intValue,
+		"vendor":                anyValue,
+		"signatureVersion":      oneOf("2", "4"),
+		"region":                anyValue,
+	}
+	// ConfigSchema is the options of each section and their validators.
+	// All of them can be overridden by the environment variables (see OverrideEnvName).
+	ConfigSchema = map[string]map[string]ConfigValidator{
+		"dagtools": {
+			"proxy":         anyValue,
+			"verbose":       boolValue,
+			"debug":         boolValue,
+			"concurrency":   intValue,
+			"tempDir":       anyValue,
+			"uploadLimit":   rateValue,
+			"downloadLimit": rateValue,
+		},
+		"logging": {
+			"type":       oneOf("none", "file", "stdout", "stderr"),
+			"file":       anyValue,
+			"level":      oneOf("error", "warn", "info", "debug"),
+			"format":     oneOf("text", "json"),
+			"maxSize":    intValue,
+			"maxBackups": intValue,
+			"maxAge":     intValue,
+			"compress":   boolValue,
+		},
+		"storage": StorageConfigSchema,
+	}
+)
+
+func anyValue(value string) error {
+	return nil
+}
+
+// boolValue accepts the same values as ini.Section.Bool (strconv.ParseBool).
+func boolValue(value string) error {
+	if _, err := strconv.ParseBool(value); err != nil {
+		return fmt.Errorf("invalid boolean value: %q (true, false)", value)
+	}
+	return nil
+}
+
+func intValue(value string) error {
+	if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+		return fmt.Errorf("invalid integer value: %q", value)
+	}
+	return nil
+}
+
+func rateValue(value string) error {
+	_, err := ParseRate(value)
+	return err
+}
+
+func statusCodesValue(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if code, err := strconv.Atoi(strings.TrimSpace(v)); err != nil || code < 100 || code > 599 {
+			return fmt.Errorf("invalid status code: %q", v)
+		}
+	}
+	return nil
+}
+
+func oneOf(values ...string) ConfigValidator {
+	return func(value string) error {
+		for _, v := range values {
+			if value == v {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid value: %q (%s)", value, strings.Join(values, ", "))
+	}
+}
+
+// ParseRate parses a rate in bytes per second with an optional suffix K, M, G or T (e.g., "512K", "20M").
+// The suffixes are multiples of 1024.
+func ParseRate(s string) (int64, error) {
+	v := strings.TrimSpace(s)
+	mul := int64(1)
+	if n := len(v); n > 0 {
+		switch strings.ToUpper(v[n-1:]) {
+		case "K":
+			mul = 1 << 10
+		case "M":
+			mul = 1 << 20
+		case "G":
+			mul = 1 << 30
+		case "T":
+			mul = 1 << 40
+		}
+		if mul > 1 {
+			v = v[:n-1]
+		}
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid rate: %q (e.g., 1048576, 512K, 20M)", s)
+	}
+	return n * mul, nil
+}
@@ -0,0 +1,146 @@
+package env
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Stats collects the statistics of the requests and the transfers of a command.
+// The methods are safe for concurrent use, and do nothing on a nil *Stats.
+type Stats struct {
+	mu          sync.Mutex
+	start       time.Time
+	requests    map[requestStat]int64
+	retries     int64
+	sent        int64
+	received    int64
+	transferred int64
+	skipped     int64
+}
+
+// requestStat is a key of the request count.
+type requestStat struct {
+	method string
+	// status is 0 if no response is received.
+	status int
+}
+
+// NewStats returns a Stats which measures the elapsed time from now.
+func NewStats() *Stats {
+	return &Stats{start: time.Now(), requests: make(map[requestStat]int64)}
+}
+
+// AddRequest counts a request of the method. The status is 0 if no response is received.
+func (s *Stats) AddRequest(method string, status int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests[requestStat{method, status}]++
+}
+
+// AddRetry counts a retry of a request.
+func (s *Stats) AddRetry() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retries++
+}
+
+// AddSent adds n bytes to the bytes sent in the request bodies.
+func (s *Stats) AddSent(n int64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent += n
+}
+
+// AddReceived adds n bytes to the bytes received in the response bodies.
+func (s *Stats) AddReceived(n int64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.received += n
+}
+
+// AddTransferred counts an object uploaded or downloaded.
+func (s *Stats) AddTransferred() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transferred++
+}
+
+// AddSkipped counts an object skipped because it has not been changed.
+func (s *Stats) AddSkipped() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.skipped++
+}
+
+// RequestCount is the number of the requests of a method and a status.
+type RequestCount struct {
+	Method string `json:"method"`
+	// Status is 0 if no response is received (e.g., a network error).
+	Status int   `json:"status"`
+	Count  int64 `json:"count"`
+}
+
+// StatsSummary is a snapshot of Stats.
+type StatsSummary struct {
+	ElapsedMs          int64          `json:"elapsedMs"`
+	Requests           []RequestCount `json:"requests"`
+	Retries            int64          `json:"retries"`
+	BytesSent          int64          `json:"bytesSent"`
+	BytesReceived      int64          `json:"bytesReceived"`
+	ObjectsTransferred int64          `json:"objectsTransferred"`
+	ObjectsSkipped     int64          `json:"objectsSkipped"`
+	// Throughput is the bytes sent and received per second.
+	Throughput float64 `json:"throughput"`
+}
+
+// Summary returns a snapshot of the statistics.
+func (s *Stats) Summary() StatsSummary {
+	if s == nil {
+		return StatsSummary{Requests: []RequestCount{}}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elapsed := time.Since(s.start)
+	sum := StatsSummary{
+		ElapsedMs:          int64(elapsed / time.Millisecond),
+		Requests:           []RequestCount{},
+		Retries:            s.retries,
+		BytesSent:          s.sent,
+		BytesReceived:      s.received,
+		ObjectsTransferred: s.transferred,
+		ObjectsSkipped:     s.skipped,
+	}
+	for k, n := range s.requests {
+		sum.Requests = append(sum.Requests, RequestCount{Method: k.method, Status: k.status, Count: n})
+	}
+	sort.Slice(sum.Requests, func(i, j int) bool {
+		a, b := sum.Requests[i], sum.Requests[j]
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.Status < b.Status
+	})
+	if elapsed > 0 {
+		sum.Throughput = float64(s.sent+s.received) / elapsed.Seconds()
+	}
+	return sum
+}
@@ -4,14 +4,23 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
+)
+
+const (
+	// includeKey is the directive to read another INI file, e.g., "include = other.ini".
+	includeKey = "include"
+	// maxIncludeDepth limits nested includes.
+	maxIncludeDepth = 10
 )
 
 var (
-	commentRegexp = regexp.MustCompile(`[;#][^"]*$`)
 	sectionRegexp = regexp.MustCompile(`^\[(.*)]`)
 	optionRegexp  = regexp.MustCompile(`^([^=:]+)[=:](.*)$`)
 )
@@ -20,10 +29,32 @@ var (
 type InvalidSyntax struct {
 	Config *Config
 	Line   int
+	// Filename is the file which has the error if it is an included file.
+	Filename string
+	// Reason describes the error (e.g., "unterminated quoted string").
+	Reason string
 }
 
 func (e InvalidSyntax) Error() string {
-	return fmt.Sprintf("syntax error in %s (line: %d)", e.Config.Filename, e.Line)
+	filename := e.Filename
+	if filename == "" {
+		filename = e.Config.Filename
+	}
+	if e.Reason != "" {
+		return fmt.Sprintf("syntax error in %s (line: %d): %s", filename, e.Line, e.Reason)
+	}
+	return fmt.Sprintf("syntax error in %s (line: %d)", filename, e.Line)
+}
+
+// InvalidValue represents an option value which cannot be converted to the type.
+type InvalidValue struct {
+	Key   string
+	Value string
+	Type  string
+}
+
+func (e InvalidValue) Error() string {
+	return fmt.Sprintf("invalid %s value of %q: %q", e.Type, e.Key, e.Value)
 }
 
 // Config contains all section in INI file.
@@ -82,6 +113,32 @@ func (c *Config) GetInt64(section string, key string, defaultValue int64) int64
 	return c.Section(section).GetInt64(key, defaultValue)
 }
 
+// Bool returns a option value as bool, or an InvalidValue error if the value is not a boolean.
+func (c *Config) Bool(section string, key string, defaultValue bool) (bool, error) {
+	v, err := c.Section(section).Bool(key, defaultValue)
+	return v, withSection(err, section)
+}
+
+// Int returns a option value as int, or an InvalidValue error if the value is not an integer.
+func (c *Config) Int(section string, key string, defaultValue int) (int, error) {
+	v, err := c.Section(section).Int(key, defaultValue)
+	return v, withSection(err, section)
+}
+
+// Int64 returns a option value as int64, or an InvalidValue error if the value is not an integer.
+func (c *Config) Int64(section string, key string, defaultValue int64) (int64, error) {
+	v, err := c.Section(section).Int64(key, defaultValue)
+	return v, withSection(err, section)
+}
+
+func withSection(err error, section string) error {
+	if e, ok := err.(InvalidValue); ok {
+		e.Key = section + "." + e.Key
+		return e
+	}
+	return err
+}
+
 // Set config
 func (c *Config) Set(section string, key string, value string) {
 	if !c.HasSection(section) {
@@ -124,62 +181,310 @@ func (s *Section) GetInt64(key string, defaultValue int64) int64 {
 	return i
 }
 
+// Bool returns a option value as bool ("true", "false", "1", "0", etc.),
+// or an InvalidValue error if the value is not a boolean.
+func (s *Section) Bool(key string, defaultValue bool) (bool, error) {
+	v, ok := (*s)[key]
+	if !ok {
+		return defaultValue, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return defaultValue, InvalidValue{Key: key, Value: v, Type: "boolean"}
+	}
+	return b, nil
+}
+
+// Int returns a option value as int, or an InvalidValue error if the value is not an integer.
+func (s *Section) Int(key string, defaultValue int) (int, error) {
+	v, ok := (*s)[key]
+	if !ok {
+		return defaultValue, nil
+	}
+	i, err := strconv.ParseInt(v, 10, 0)
+	if err != nil {
+		return defaultValue, InvalidValue{Key: key, Value: v, Type: "integer"}
+	}
+	return int(i), nil
+}
+
+// Int64 returns a option value as int64, or an InvalidValue error if the value is not an integer.
+func (s *Section) Int64(key string, defaultValue int64) (int64, error) {
+	v, ok := (*s)[key]
+	if !ok {
+		return defaultValue, nil
+	}
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return defaultValue, InvalidValue{Key: key, Value: v, Type: "integer"}
+	}
+	return i, nil
+}
+
+// Duration returns a option value as time.Duration (e.g., "1h30m"), or an InvalidValue error if the value is invalid.
+func (s *Section) Duration(key string, defaultValue time.Duration) (time.Duration, error) {
+	v, ok := (*s)[key]
+	if !ok {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return defaultValue, InvalidValue{Key: key, Value: v, Type: "duration"}
+	}
+	return d, nil
+}
+
 // Set config
 func (s *Section) Set(key string, value string) {
 	(*s)[key] = value
 }
 
-func parseFile(c Config) error {
-	in, err := os.Open(c.Filename)
+// parser reads an INI file and the included files into a Config.
+type parser struct {
+	config *Config
+	// including is a set of files being parsed to detect circular includes.
+	including map[string]bool
+}
+
+// parseFile parses the file. Options before the first section header belong to the section (e.g., an included file).
+func (p *parser) parseFile(filename, section string) error {
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return err
+	}
+	if p.including[path] {
+		return fmt.Errorf("circular include: %s", filename)
+	}
+	if len(p.including) >= maxIncludeDepth {
+		return fmt.Errorf("too many nested includes: %s", filename)
+	}
+	in, err := os.Open(filename)
 	if err != nil {
 		return err
 	}
-	bufin := bufio.NewReader(in)
 	defer in.Close()
-	var (
-		section   *Section
-		line      string
-		wasPrefix = false
-		lineNum   = 0
-	)
+	p.including[path] = true
+	defer delete(p.including, path)
+
+	syntaxError := func(line int, reason string) error {
+		e := InvalidSyntax{Config: p.config, Line: line, Reason: reason}
+		if filename != p.config.Filename {
+			e.Filename = filename
+		}
+		return e
+	}
+	reader := bufio.NewReader(in)
+	lineNum := 0
 	for {
-		buf, isPrefix, err := bufin.ReadLine()
-		if err != nil {
+		line, err := readLine(reader)
+		if err == io.EOF {
 			break
+		} else if err != nil {
+			return err
 		}
-		if wasPrefix {
-			line += string(buf)
-		} else {
-			line = string(buf)
-		}
-		if !isPrefix {
-			line = strings.TrimSpace(line)
-			line = commentRegexp.ReplaceAllString(line, "")
+		lineNum++
+		start := lineNum
+		// join continuation lines
+		for isContinued(line) {
+			next, err := readLine(reader)
+			if err == io.EOF {
+				return syntaxError(start, "no line after the line continuation")
+			} else if err != nil {
+				return err
+			}
 			lineNum++
-			if len(line) == 0 {
-				// Skip blank lines
-				continue
+			line = strings.TrimRight(line, " \t")
+			line = line[:len(line)-1] + strings.TrimLeft(next, " \t")
+		}
+		line, _ = splitComment(strings.TrimSpace(line))
+		if line == "" {
+			// Skip blank lines
+			continue
+		}
+		if groups := sectionRegexp.FindStringSubmatch(line); groups != nil {
+			section = strings.TrimSpace(groups[1])
+			p.config.NewSection(section)
+			continue
+		}
+		groups := optionRegexp.FindStringSubmatch(line)
+		if groups == nil {
+			return syntaxError(start, "")
+		}
+		key := strings.TrimSpace(groups[1])
+		value, err := parseValue(strings.TrimSpace(groups[2]))
+		if err != nil {
+			return syntaxError(start, err.Error())
+		}
+		if key == includeKey {
+			if !filepath.IsAbs(value) {
+				value = filepath.Join(filepath.Dir(filename), value)
 			}
-			if groups := sectionRegexp.FindStringSubmatch(line); groups != nil {
-				name := strings.TrimSpace(groups[1])
-				section = c.NewSection(name)
-			} else if groups := optionRegexp.FindStringSubmatch(line); groups != nil {
-				key, val := strings.TrimSpace(groups[1]), strings.TrimSpace(groups[2])
-				if section != nil {
-					section.Set(key, strings.Trim(val, `"`))
+			if err := p.parseFile(value, section); err != nil {
+				if _, ok := err.(InvalidSyntax); ok {
+					return err
 				}
-			} else {
-				return InvalidSyntax{Config: &c, Line: lineNum}
+				return syntaxError(start, err.Error())
 			}
+			continue
+		}
+		if section != "" {
+			p.config.NewSection(section).Set(key, value)
 		}
-		wasPrefix = isPrefix
 	}
 	return nil
 }
 
+// readLine returns a line without the line break.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// isContinued returns true if the line ends with a backslash (except comment lines).
+func isContinued(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || trimmed[0] == '#' || trimmed[0] == ';' {
+		return false
+	}
+	return strings.HasSuffix(trimmed, "\\")
+}
+
+// splitComment splits the line into the content and the comment. A comment starts with '#' or ';'
+// at the beginning of the line or after a space, and outside of a quoted value.
+func splitComment(line string) (content, comment string) {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && isValueStart(line, i):
+			quote = c
+		case (c == '#' || c == ';') && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return strings.TrimRight(line[:i], " \t"), line[i:]
+		}
+	}
+	return line, ""
+}
+
+// isValueStart returns true if the i-th character starts a value, i.e., it follows '=' or ':' and spaces.
+func isValueStart(line string, i int) bool {
+	before := strings.TrimRight(line[:i], " \t")
+	return strings.HasSuffix(before, "=") || strings.HasSuffix(before, ":")
+}
+
+// parseValue returns the value of an option:
+//   - "double quoted": escape sequences (\\, \", \$, \n, \r, \t) and ${ENV} are interpreted.
+//   - 'single quoted': the value is used literally.
+//   - unquoted: ${ENV} is interpreted.
+func parseValue(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	switch s[0] {
+	case '\'':
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted string")
+		}
+		if rest := strings.TrimSpace(s[end+2:]); rest != "" {
+			return "", fmt.Errorf("unexpected characters after the quoted string: %q", rest)
+		}
+		return s[1 : end+1], nil
+	case '"':
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			c := s[i]
+			switch c {
+			case '"':
+				if rest := strings.TrimSpace(s[i+1:]); rest != "" {
+					return "", fmt.Errorf("unexpected characters after the quoted string: %q", rest)
+				}
+				return b.String(), nil
+			case '\\':
+				if i+1 == len(s) {
+					return "", fmt.Errorf("unterminated quoted string")
+				}
+				i++
+				switch s[i] {
+				case '\\', '"', '$':
+					b.WriteByte(s[i])
+				case 'n':
+					b.WriteByte('\n')
+				case 'r':
+					b.WriteByte('\r')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					return "", fmt.Errorf("invalid escape sequence: \\%c", s[i])
+				}
+			case '$':
+				v, n, err := expandVariable(s[i:])
+				if err != nil {
+					return "", err
+				}
+				b.WriteString(v)
+				i += n - 1
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", fmt.Errorf("unterminated quoted string")
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' {
+			b.WriteByte(s[i])
+			continue
+		}
+		v, n, err := expandVariable(s[i:])
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(v)
+		i += n - 1
+	}
+	return b.String(), nil
+}
+
+// expandVariable expands "${NAME}" or "${NAME:-default}" at the beginning of s with the environment variable,
+// and returns the value and the length of the reference. A "$" without "{" is returned as is.
+func expandVariable(s string) (value string, n int, err error) {
+	if !strings.HasPrefix(s, "${") {
+		return "$", 1, nil
+	}
+	end := strings.IndexByte(s, '}')
+	if end < 0 {
+		return "", 0, fmt.Errorf("unterminated variable reference: %q", s)
+	}
+	name, defaultValue, hasDefault := s[2:end], "", false
+	if i := strings.Index(name, ":-"); i >= 0 {
+		name, defaultValue, hasDefault = name[:i], name[i+2:], true
+	}
+	if name == "" {
+		return "", 0, fmt.Errorf("empty variable name: %q", s[:end+1])
+	}
+	v, ok := os.LookupEnv(name)
+	if !ok || (v == "" && hasDefault) {
+		if !hasDefault {
+			return "", 0, fmt.Errorf("undefined environment variable: %s", name)
+		}
+		v = defaultValue
+	}
+	return v, end + 1, nil
+}
+
 // LoadFile returns Config of loaded INI file.
 func LoadFile(filename string) (Config, error) {
 	c := Config{Filename: filename, Sections: make(map[string]Section)}
-	err := parseFile(c)
+	p := &parser{config: &c, including: make(map[string]bool)}
+	err := p.parseFile(filename, "")
 	return c, err
 }
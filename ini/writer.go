@@ -0,0 +1,144 @@
+package ini
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// Document is an INI file which keeps the comments, blank lines and the order of options,
+// so that the values can be changed and written back without losing them.
+type Document struct {
+	lines []string
+}
+
+// ReadDocument reads an INI file as a Document.
+func ReadDocument(r io.Reader) (*Document, error) {
+	d := &Document{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 4096), 1024*1024)
+	for scanner.Scan() {
+		d.lines = append(d.lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// LoadDocument reads an INI file of the filename as a Document.
+func LoadDocument(filename string) (*Document, error) {
+	in, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	return ReadDocument(in)
+}
+
+// Set changes the value of the option. The comment after the value is kept.
+// If the option does not exist, it is added at the end of the section (or a new section at the end of the document).
+func (d *Document) Set(section, key, value string) {
+	var (
+		current     string
+		optionStart = -1
+		optionEnd   = -1
+		optionLine  string
+		lastLine    = -1
+	)
+	for i := 0; i < len(d.lines); i++ {
+		start, line := i, d.lines[i]
+		// join continuation lines
+		for isContinued(line) && i+1 < len(d.lines) {
+			i++
+			line = strings.TrimRight(line, " \t")
+			line = line[:len(line)-1] + strings.TrimLeft(d.lines[i], " \t")
+		}
+		stripped, _ := splitComment(strings.TrimSpace(line))
+		if groups := sectionRegexp.FindStringSubmatch(stripped); groups != nil {
+			current = strings.TrimSpace(groups[1])
+			if current == section {
+				lastLine = i
+			}
+			continue
+		}
+		if current != section || stripped == "" {
+			continue
+		}
+		lastLine = i
+		if groups := optionRegexp.FindStringSubmatch(stripped); groups != nil && strings.TrimSpace(groups[1]) == key {
+			optionStart, optionEnd, optionLine = start, i, line
+		}
+	}
+	switch {
+	case optionStart >= 0:
+		lines := append([]string{}, d.lines[:optionStart]...)
+		lines = append(lines, replaceValue(optionLine, value))
+		d.lines = append(lines, d.lines[optionEnd+1:]...)
+	case lastLine >= 0:
+		d.lines = append(d.lines[:lastLine+1], append([]string{formatOption(key, value)}, d.lines[lastLine+1:]...)...)
+	default:
+		if len(d.lines) > 0 && strings.TrimSpace(d.lines[len(d.lines)-1]) != "" {
+			d.lines = append(d.lines, "")
+		}
+		d.lines = append(d.lines, "["+section+"]", formatOption(key, value))
+	}
+}
+
+// WriteTo writes the document to w.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, d.String())
+	return int64(n), err
+}
+
+// String returns the content of the document.
+func (d *Document) String() string {
+	if len(d.lines) == 0 {
+		return ""
+	}
+	return strings.Join(d.lines, "\n") + "\n"
+}
+
+// Save writes the document to the file. The file is created with the permission if it does not exist.
+func (d *Document) Save(filename string, perm os.FileMode) error {
+	out, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err = d.WriteTo(out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// replaceValue replaces the value of the option line keeping the indent, the separator and the comment.
+func replaceValue(line, value string) string {
+	trimmed := strings.TrimSpace(line)
+	indent := line[:strings.Index(line, trimmed)]
+	option, comment := splitComment(trimmed)
+	spacing := trimmed[len(option) : len(trimmed)-len(comment)]
+	if comment != "" && spacing == "" {
+		spacing = " "
+	}
+	sep := strings.IndexAny(option, "=:")
+	rest := option[sep+1:]
+	prefix := option[:sep+1] + rest[:len(rest)-len(strings.TrimLeft(rest, " \t"))]
+	return indent + prefix + QuoteValue(value) + spacing + comment
+}
+
+func formatOption(key, value string) string {
+	return key + " = " + QuoteValue(value)
+}
+
+var valueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// QuoteValue returns the value enclosed in double quotes with escape sequences
+// if it has special characters (comment characters, quotes, '\', '$' or control characters) or surrounding spaces.
+func QuoteValue(value string) string {
+	if strings.ContainsAny(value, "#;\"'\\$\n\r\t") || strings.TrimSpace(value) != value {
+		return `"` + valueEscaper.Replace(value) + `"`
+	}
+	return value
+}
@@ -0,0 +1,102 @@
+package ini
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDocumentRoundTrip(t *testing.T) {
+	b, _ := ioutil.ReadFile("test_files/sample.ini")
+	d, err := LoadDocument("test_files/sample.ini")
+	if err != nil {
+		t.Errorf("Failed to load INI file: %s", err)
+	}
+	assertEquals(t, d.String(), string(b))
+}
+
+func TestDocumentSet(t *testing.T) {
+	d, _ := ReadDocument(strings.NewReader(`[foo]
+o1=value1
+o2 = value2
+o3:value3
+o4 : value4
+
+[bar]
+o5 = "value5  "
+# comment
+  o6 = value6 ## comment
+`))
+	d.Set("foo", "o1", "new1")
+	d.Set("foo", "o4", "new4")
+	d.Set("bar", "o6", "new6")
+	d.Set("bar", "o7", "a#b")
+	d.Set("foo", "o8", "new8")
+	d.Set("baz", "o9", "new9")
+	expected := `[foo]
+o1=new1
+o2 = value2
+o3:value3
+o4 : new4
+o8 = new8
+
+[bar]
+o5 = "value5  "
+# comment
+  o6 = new6 ## comment
+o7 = "a#b"
+
+[baz]
+o9 = new9
+`
+	assertEquals(t, d.String(), expected)
+
+	dir, _ := ioutil.TempDir("", "dagtools-ini")
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "dagtools.ini")
+	if err := d.Save(filename, 0600); err != nil {
+		t.Errorf("Failed to save INI file: %s", err)
+	}
+	c, err := LoadFile(filename)
+	if err != nil {
+		t.Errorf("Failed to load INI file: %s", err)
+	}
+	assertEquals(t, c.Get("bar", "o6", ""), "new6")
+	assertEquals(t, c.Get("bar", "o7", ""), "a#b")
+	assertEquals(t, c.Get("baz", "o9", ""), "new9")
+	stat, _ := os.Stat(filename)
+	assertEquals(t, stat.Mode().Perm(), os.FileMode(0600))
+}
+
+func TestDocumentSetEmpty(t *testing.T) {
+	d, _ := ReadDocument(strings.NewReader(""))
+	d.Set("storage", "endpoint", "storage-dag.iijgio.com")
+	assertEquals(t, d.String(), "[storage]\nendpoint = storage-dag.iijgio.com\n")
+}
+
+func TestQuoteValue(t *testing.T) {
+	for _, value := range []string{"plain", "a#b", " spaces ", `"quoted"`, `C:\dir`, "${HOME}", "tab\tnew\nline", "it's"} {
+		d, _ := ReadDocument(strings.NewReader(""))
+		d.Set("foo", "o1", value)
+		c, err := parse(d.String())
+		if err != nil {
+			t.Errorf("Failed to parse %q: %s", d.String(), err)
+			continue
+		}
+		assertEquals(t, c.Get("foo", "o1", ""), value)
+	}
+	assertEquals(t, QuoteValue("plain"), "plain")
+}
+
+func parse(content string) (Config, error) {
+	f, err := ioutil.TempFile("", "dagtools-ini")
+	if err != nil {
+		return Config{}, err
+	}
+	defer os.Remove(f.Name())
+	f.WriteString(content)
+	f.Close()
+	return LoadFile(f.Name())
+}
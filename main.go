@@ -17,7 +17,7 @@ var (
 
 // Usage prints a command usage of the dagtools.
 func Usage(out *os.File) {
-	fmt.Fprintln(out, "Usage:\n  dagtools [-h] [-d] [-v] [-f <config file>] <command> [<args>]\n\nOptions:")
+	fmt.Fprintln(out, "Usage:\n  dagtools [-h] [-d] [-v] [-f <config file>] [-profile <name>] [-stats[=json]] <command> [<args>]\n\nOptions:")
 	commandLine.PrintDefaults()
 	fmt.Fprintln(out, "\nCommands:")
 	for name, _cmd := range cmd.Commands.All() {
@@ -26,6 +26,36 @@ func Usage(out *os.File) {
 	fmt.Fprintln(out, "")
 }
 
+// statsFlag is the -stats option. "-stats" is the same as "-stats=table".
+type statsFlag struct {
+	format *string
+}
+
+func (f *statsFlag) String() string {
+	if f.format == nil {
+		return ""
+	}
+	return *f.format
+}
+
+func (f *statsFlag) Set(v string) error {
+	switch v {
+	case "true", "table":
+		*f.format = "table"
+	case "json":
+		*f.format = "json"
+	case "false":
+		*f.format = ""
+	default:
+		return fmt.Errorf("invalid format: %q (table, json)", v)
+	}
+	return nil
+}
+
+func (f *statsFlag) IsBoolFlag() bool {
+	return true
+}
+
 func main() {
 	var (
 		configFile         string
@@ -36,6 +66,8 @@ func main() {
 		verbose            = false
 		version            = false
 		debug              = false
+		profile            = ""
+		stats              = ""
 	)
 	commandLine = flag.NewFlagSet("dagtools", flag.ExitOnError)
 	commandLine.Usage = func() {
@@ -46,6 +78,8 @@ func main() {
 	commandLine.BoolVar(&version, "version", false, "show version")
 	commandLine.BoolVar(&debug, "d", false, "debug mode")
 	commandLine.StringVar(&configFile, "f", "", "specify an alternate configuration file (default: ./dagtools.ini or /etc/dagtools.ini)")
+	commandLine.StringVar(&profile, "profile", "", "use the storage settings of the profile (default: $"+env.EnvProfile+")")
+	commandLine.Var(&statsFlag{&stats}, "stats", "print the transfer statistics to stderr after the command (-stats or -stats=json)")
 	commandLine.Parse(os.Args[1:])
 	args := commandLine.Args()
 
@@ -67,26 +101,52 @@ func main() {
 		config, err = ini.LoadFile(configFile)
 	} else {
 		for _, filename := range defaultConfigFiles {
-			if config, err = ini.LoadFile(filename); err == nil {
+			if config, err = ini.LoadFile(filename); !os.IsNotExist(err) {
 				break
 			}
 		}
 	}
+	if err != nil && os.IsNotExist(err) {
+		switch {
+		case cmdName == "config":
+			// "config init" creates the file
+			if configFile == "" {
+				configFile = defaultConfigFiles[0]
+			}
+			config, err = ini.Config{Filename: configFile, Sections: make(map[string]ini.Section)}, nil
+		case configFile == "":
+			// the settings are given by the environment variables (DAGTOOLS_<SECTION>_<KEY>)
+			config, err = ini.Config{Sections: make(map[string]ini.Section)}, nil
+		}
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "[Error] %v\n", err.Error())
 		os.Exit(1)
 	}
 	e := env.Environment{
-		Debug:   debug,
-		Verbose: verbose,
-		Config:  &config,
+		Debug:       debug,
+		Verbose:     verbose,
+		Profile:     profile,
+		Config:      &config,
+		StatsFormat: stats,
 	}
-	defer e.Close()
 	err = e.Init()
+	if _, ok := err.(ini.InvalidValue); ok && cmdName == "config" {
+		// "config validate" reports the invalid values
+		err = nil
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "[Error]: %v\n", err)
+		e.Close()
 		os.Exit(1)
 	}
+	os.Exit(run(&e, cmdName, cmdArgs))
+}
+
+// run runs the command and closes the environment, e.g., waits for the compression of the rotated log files.
+// The environment is closed here because the deferred calls of main do not run on os.Exit.
+func run(e *env.Environment, cmdName string, cmdArgs []string) int {
+	defer e.Close()
 	defer func() {
 		if e.Debug {
 			return
@@ -95,5 +155,5 @@ func main() {
 			fmt.Fprintf(os.Stderr, "[Error]: %v\n", err)
 		}
 	}()
-	os.Exit(cmd.Run(&e, cmdName, cmdArgs))
+	return cmd.Run(e, cmdName, cmdArgs)
 }